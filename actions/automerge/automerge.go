@@ -7,29 +7,21 @@ import (
 
 	"github.com/google/go-github/github"
 	"github.com/mtraver/gaelog"
+	"github.com/octo/ghbot/actions/labelsync"
 	"github.com/octo/ghbot/client"
 	"github.com/octo/ghbot/event"
+	"github.com/octo/ghbot/scheduler"
 )
 
 const automergeLabel = "Automerge"
 
-// requiredStatuses is a list of all status "contexts" that must signal success
-// before a PR can automatically be merged.
-// TODO(octo): may be redundant with the "Require status checks to pass before merging" setting.
-var requiredStatuses = []string{
-	"ChangeLog",
-	"clang-format",
-}
-
-var requiredChecks = []string{
-	"make_distcheck",
-}
-
 func init() {
 	event.CheckSuiteHandler("automerge", processCheckSuite)
 	event.PullRequestHandler("automerge", processPullRequestEvent)
 	event.PullRequestReviewHandler("automerge", processReviewEvent)
 	event.StatusHandler("automerge", processStatusEvent)
+	labelsync.Require("automerge", automergeLabel)
+	scheduler.Register("mergequeue", "*/15 * * * *", processTimeouts)
 }
 
 func processCheckSuite(ctx context.Context, event *github.CheckSuiteEvent) error {
@@ -74,7 +66,18 @@ func processPullRequestEvent(ctx context.Context, event *github.PullRequestEvent
 		return err
 	}
 
-	return process(ctx, c, c.WrapPR(event.PullRequest))
+	pr := c.WrapPR(event.PullRequest)
+
+	// Record pushes to all pull requests, so that the time is known when
+	// the Automerge label is added later.
+	switch event.GetAction() {
+	case "opened", "reopened", "synchronize":
+		if _, err := headUpdated(ctx, c, pr); err != nil {
+			return err
+		}
+	}
+
+	return process(ctx, c, pr)
 }
 
 func processReviewEvent(ctx context.Context, e *github.PullRequestReviewEvent) error {
@@ -109,17 +112,15 @@ func processStatusEvent(ctx context.Context, event *github.StatusEvent) error {
 
 // process merges a pull request, if:
 // * It it still open and has not already been merged.
-// * Is has the Automerge label.
-// * There are no outstanding reviews (CHANGES_REQUESTED or PENDING).
-// * The overall state is "success".
-// * All required checks have succeeded.
-// * There are no merge conflicts.
-func process(ctx context.Context, client *client.Client, pr *client.PR) error {
+// * Is has the Automerge label, or e.g. "Automerge: squash".
+// * It satisfies the merge policy, see loadPolicy.
+// * It is at the head of its base branch's merge queue and up to date.
+func process(ctx context.Context, c *client.Client, pr *client.PR) error {
 	gaelog.Debugf(ctx, "checking if %v can be automerged", pr)
 
 	if pr.GetMerged() || pr.GetState() != "open" {
 		gaelog.Debugf(ctx, "automerge: no, not open")
-		return nil
+		return dequeue(ctx, c, pr)
 	}
 
 	issue, err := pr.Issue(ctx)
@@ -127,146 +128,69 @@ func process(ctx context.Context, client *client.Client, pr *client.PR) error {
 		return err
 	}
 
-	if !issue.HasLabel(automergeLabel) {
-		gaelog.Debugf(ctx, "automerge: no, does not have the %q label", automergeLabel)
-		return nil
-	}
-
-	if ok, err := allReviewsFinished(ctx, client, pr); !ok || err != nil {
-		if err != nil {
-			return err
-		}
-		gaelog.Debugf(ctx, "automerge: no, there are unfinished reviews")
-		return nil
-	}
-
-	status, err := pr.CombinedStatus(ctx)
-	if err != nil {
-		return err
-	}
-
-	success := map[string]bool{}
-	for _, s := range status.Statuses {
-		gaelog.Debugf(ctx, "automerge: status %q => %q", s.GetContext(), s.GetState())
-		success[s.GetContext()] = (s.GetState() == "success")
+	var labels []string
+	for _, l := range issue.Labels {
+		labels = append(labels, l.GetName())
 	}
 
-	for _, req := range requiredStatuses {
-		if !success[req] {
-			gaelog.Debugf(ctx, "automerge: no, check %q missing or not successful", req)
-			return nil
-		}
-	}
-
-	if s := status.GetState(); s != "success" {
-		gaelog.Debugf(ctx, "automerge: no, overall status is %q", s)
-		return nil
-	}
-
-	ok, err := haveRequiredChecks(ctx, client, pr)
-	if err != nil {
-		return err
-	}
+	method, label, ok := mergeMethod(repoName(c), labels)
 	if !ok {
-		gaelog.Debugf(ctx, "automerge: no, required checks are missing or unsuccessful")
-		return nil
+		gaelog.Debugf(ctx, "automerge: no, does not have the %q label", automergeLabel)
+		return dequeue(ctx, c, pr)
 	}
 
-	ok, err = pr.Mergeable(ctx)
+	cfg, err := loadPolicy(ctx, c, pr)
 	if err != nil {
 		return err
 	}
-	if !ok {
-		gaelog.Debugf(ctx, "automerge: no, has merge conflicts")
-		return nil
-	}
-
-	gaelog.Infof(ctx, "merging %v", pr)
-	title := fmt.Sprintf("Auto-Merge pull request %v from %s/%s", pr, pr.Head.User.GetLogin(), pr.Head.GetRef())
-	msg := fmt.Sprintf("Automatically merged due to %q label", automergeLabel)
-	return pr.Merge(ctx, title, msg)
-}
-
-func haveRequiredChecks(ctx context.Context, client *client.Client, pr *client.PR) (bool, error) {
-	checkRuns, err := client.CheckRuns(ctx, pr.GetHead().GetSHA())
+	in, err := newInput(ctx, c, pr, cfg, labels)
 	if err != nil {
-		return false, err
+		return err
 	}
 
-	byName := make(map[string]*github.CheckRun)
-	for _, cr := range checkRuns {
-		gaelog.Debugf(ctx, "automerge: Check %q -> %q", cr.GetName(), cr.GetConclusion())
-		byName[cr.GetName()] = cr
+	report := cfg.Policy().Evaluate(in)
+	for _, res := range report {
+		gaelog.Debugf(ctx, "automerge: %v", res)
 	}
 
-	ret := true
-	for _, name := range requiredChecks {
-		cr, ok := byName[name]
-		if !ok {
-			gaelog.Warningf(ctx, "automerge: Required check %q was not reported by GitHub.", name)
-			ret = false
+	if report.OK() || report.Pending() {
+		// Branches using Github's merge queue are merged by Github.
+		state, err := pr.MergeQueueState(ctx)
+		if err != nil {
+			return err
 		}
-		if cr.GetConclusion() != "success" {
-			gaelog.Debugf(ctx, "automerge: Check %q was not successful", name)
-			ret = false
+		if state.Enabled {
+			return processNativeQueue(ctx, c, pr, state, method, report)
 		}
 	}
 
-	return ret, nil
-}
-
-func allReviewsFinished(ctx context.Context, client *client.Client, pr *client.PR) (bool, error) {
-	var (
-		reviewsOpts github.ListOptions
-		isApproved  bool
-	)
-	for {
-		reviews, resp, err := client.PullRequests.ListReviews(ctx, client.Owner(), client.Repo(), pr.GetNumber(), &reviewsOpts)
-		if err != nil {
-			return false, fmt.Errorf("ListReviews(\"%s/%s#%d\"): %w", client.Owner(), client.Repo(), pr.GetNumber(), err)
-		}
-
-		for _, r := range reviews {
-			gaelog.Debugf(ctx, "automerge: PR #%d: Review by %s is in state %s", pr.GetNumber(), r.GetUser().GetLogin(), r.GetState())
+	if report.OK() {
+		return processQueue(ctx, c, pr, method, label, report)
+	}
 
-			switch r.GetState() {
-			case "CHANGES_REQUESTED":
-				return false, nil
-			case "APPROVED":
-				isApproved = true
-			}
-		}
-		if resp.NextPage == 0 {
-			break
-		}
-		reviewsOpts.Page = resp.NextPage
+	conclusion := client.ConclusionFailure
+	if report.Pending() {
+		conclusion = client.ConclusionNeutral
 	}
+	publishCheck(ctx, c, pr, conclusion, reportTitle(report), report)
 
-	if !isApproved {
-		gaelog.Debugf(ctx, "automerge: PR #%d: no approved reviews", pr.GetNumber())
-		return false, nil
+	// Pull requests in the queue keep their position while waiting for
+	// checks, e.g. after their branch has been updated.
+	if report.Pending() {
+		gaelog.Debugf(ctx, "automerge: not yet, waiting for pending conditions")
+		return nil
 	}
 
-	var (
-		commentsOpts  github.PullRequestListCommentsOptions
-		commentsFound bool
-	)
-	for {
-		comments, resp, err := client.PullRequests.ListComments(ctx, client.Owner(), client.Repo(), pr.GetNumber(), &commentsOpts)
+	if !in.Mergeable {
+		files, err := pr.ConflictingFiles(ctx)
 		if err != nil {
-			return false, fmt.Errorf("ListComments(\"%s/%s#%d\"): %w", client.Owner(), client.Repo(), pr.GetNumber(), err)
-		}
-
-		for _, c := range comments {
-			gaelog.Debugf(ctx, "automerge: PR #%d: Found review comment from %s: %s", pr.GetNumber(), c.GetUser().GetLogin(), c.GetHTMLURL())
-			commentsFound = true
+			return err
 		}
-
-		if resp.NextPage == 0 {
-			break
+		if err := reportConflict(ctx, c, pr, files); err != nil {
+			return err
 		}
-		reviewsOpts.Page = resp.NextPage
 	}
 
-	return !commentsFound, nil
+	gaelog.Debugf(ctx, "automerge: no, merge policy is not satisfied")
+	return dequeue(ctx, c, pr)
 }
@@ -0,0 +1,50 @@
+package automerge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mtraver/gaelog"
+	"github.com/octo/ghbot/client"
+	"github.com/octo/ghbot/policy"
+)
+
+const checkName = "Automerge"
+
+// publishCheck sets the "Automerge" status on the head of pr, explaining
+// which merge conditions are met and which are not. Errors are logged but
+// otherwise ignored, because they must not prevent the merge.
+func publishCheck(ctx context.Context, c *client.Client, pr *client.PR, conclusion, title string, report policy.Report) {
+	check := client.Check{
+		Name:       checkName,
+		Conclusion: conclusion,
+		Title:      title,
+		Summary:    formatReport(report),
+	}
+
+	if err := pr.SetCheck(ctx, check); err != nil {
+		gaelog.Warningf(ctx, "automerge: publishing check failed: %v", err)
+	}
+}
+
+// reportTitle returns a short description of report.
+func reportTitle(report policy.Report) string {
+	failed := report.Failed()
+	if len(failed) == 0 {
+		return "All merge conditions are met"
+	}
+	if report.Pending() {
+		return fmt.Sprintf("Waiting for %d of %d conditions", len(failed), len(report))
+	}
+	return fmt.Sprintf("Not merging: %d of %d conditions not met", len(failed), len(report))
+}
+
+// formatReport formats report as a Markdown list.
+func formatReport(report policy.Report) string {
+	var b strings.Builder
+	for _, res := range report {
+		fmt.Fprintf(&b, "* %v\n", res)
+	}
+	return b.String()
+}
@@ -0,0 +1,175 @@
+package automerge
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mtraver/gaelog"
+	"github.com/octo/ghbot/client"
+	"github.com/octo/ghbot/policy"
+)
+
+const (
+	codeOwnersPath = ".github/CODEOWNERS"
+	policyPath     = ".github/automerge.json"
+)
+
+// defaultPolicy is the policy a pull request has to satisfy before it is
+// merged automatically, unless the repository has a policyPath file.
+var defaultPolicy = policy.Config{
+	MinApprovals:       1,
+	NoChangesRequested: true,
+	NoReviewComments:   true,
+	RequiredChecks: []string{
+		"ChangeLog",
+		"clang-format",
+		"make_distcheck",
+	},
+	CombinedStatus: true,
+	// Conventional Commits is advisory: the repository has plenty of
+	// history in the "foo plugin: Did a thing." style.
+	CombinedStatusIgnore: []string{"Conventional Commits"},
+	Mergeable:            true,
+}
+
+// loadPolicy returns the merge policy from policyPath on the base branch of
+// pr, so that a pull request cannot change its own policy. If the file does
+// not exist, defaultPolicy is returned.
+func loadPolicy(ctx context.Context, c *client.Client, pr *client.PR) (policy.Config, error) {
+	content, err := c.FileContent(ctx, policyPath, pr.GetBase().GetRef())
+	if err == os.ErrNotExist {
+		return defaultPolicy, nil
+	}
+	if err != nil {
+		return policy.Config{}, err
+	}
+
+	cfg, err := policy.Parse([]byte(content))
+	if err != nil {
+		return policy.Config{}, fmt.Errorf("%s: %w", policyPath, err)
+	}
+	return cfg, nil
+}
+
+// newInput collects the state of pr that the policy cfg is evaluated against.
+func newInput(ctx context.Context, c *client.Client, pr *client.PR, cfg policy.Config, labels []string) (*policy.Input, error) {
+	in := &policy.Input{
+		Author:   pr.GetUser().GetLogin(),
+		Base:     pr.GetBase().GetRef(),
+		Labels:   labels,
+		Statuses: map[string]string{},
+		Checks:   map[string]string{},
+	}
+
+	reviews, err := pr.Reviews(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var all []policy.Review
+	for _, r := range reviews {
+		gaelog.Debugf(ctx, "automerge: PR #%d: Review by %s is in state %s", pr.GetNumber(), r.GetUser().GetLogin(), r.GetState())
+		all = append(all, policy.Review{
+			Login:       r.GetUser().GetLogin(),
+			State:       r.GetState(),
+			SubmittedAt: r.GetSubmittedAt(),
+			CommitID:    r.GetCommitID(),
+		})
+	}
+	var headSHA string
+	if cfg.DismissStaleReviews {
+		headSHA = pr.GetHead().GetSHA()
+	}
+	in.Reviews = policy.LatestReviews(all, headSHA)
+
+	threads, err := pr.ReviewThreads(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range threads {
+		if t.IsResolved {
+			continue
+		}
+		gaelog.Debugf(ctx, "automerge: PR #%d: Found unresolved review comment from %s: %s", pr.GetNumber(), t.Author, t.URL)
+		in.Comments = append(in.Comments, policy.Comment{
+			Login: t.Author,
+			URL:   t.URL,
+		})
+	}
+
+	status, err := pr.CombinedStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range status.Statuses {
+		in.Statuses[s.GetContext()] = s.GetState()
+	}
+	// The "Automerge" status is pending while automerge waits, so it must
+	// not count towards the statuses automerge is waiting for.
+	delete(in.Statuses, checkName)
+	in.CombinedState = policy.CombinedState(in.Statuses)
+
+	checkRuns, err := c.CheckRuns(ctx, pr.GetHead().GetSHA())
+	if err != nil {
+		return nil, err
+	}
+	for _, cr := range checkRuns {
+		in.Checks[cr.GetName()] = cr.GetConclusion()
+	}
+
+	if in.Mergeable, err = pr.Mergeable(ctx); err != nil {
+		return nil, err
+	}
+
+	if in.LastPush, err = headUpdated(ctx, c, pr); err != nil {
+		return nil, err
+	}
+
+	mergePolicy := cfg.Policy()
+	teams := mergePolicy.Teams()
+	if mergePolicy.NeedsCodeOwners() {
+		if err := addCodeOwners(ctx, c, pr, in); err != nil {
+			return nil, err
+		}
+		teams = append(teams, in.CodeOwners.Teams()...)
+	}
+
+	in.TeamMembers = map[string][]string{}
+	for _, team := range teams {
+		if _, ok := in.TeamMembers[team]; ok {
+			continue
+		}
+
+		members, err := c.TeamMembers(ctx, team)
+		if err != nil {
+			return nil, err
+		}
+		in.TeamMembers[team] = members
+	}
+
+	return in, nil
+}
+
+// addCodeOwners sets in.CodeOwners and in.Files. The CODEOWNERS file is read
+// from the base branch, so that a pull request cannot change its own owners.
+func addCodeOwners(ctx context.Context, c *client.Client, pr *client.PR, in *policy.Input) error {
+	content, err := c.FileContent(ctx, codeOwnersPath, pr.GetBase().GetRef())
+	if err == os.ErrNotExist {
+		in.CodeOwners = policy.ParseCodeOwners("")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	in.CodeOwners = policy.ParseCodeOwners(content)
+
+	files, err := pr.Files(ctx)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		in.Files = append(in.Files, f.Filename)
+	}
+
+	return nil
+}
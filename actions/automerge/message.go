@@ -0,0 +1,161 @@
+package automerge
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/octo/ghbot/actions/changelog"
+	"github.com/octo/ghbot/client"
+)
+
+const defaultMergeMethod = "merge"
+
+// mergeMethods maps "owner/repo" to the merge method used for that
+// repository. It can be overridden per pull request with a label like
+// "Automerge: squash".
+var mergeMethods = map[string]string{
+	"collectd/collectd": "merge",
+}
+
+var validMergeMethods = map[string]bool{
+	"merge":  true,
+	"squash": true,
+	"rebase": true,
+}
+
+// titleTemplates and bodyTemplates hold the commit title and message used for
+// each merge method. "rebase" merges ignore both.
+var (
+	titleTemplates = map[string]*template.Template{
+		"merge":  template.Must(template.New("title").Parse(`Auto-Merge pull request #{{.Number}} from {{.HeadOwner}}/{{.HeadRef}}`)),
+		"squash": template.Must(template.New("title").Parse(`{{.Title}} (#{{.Number}})`)),
+	}
+	bodyTemplates = map[string]*template.Template{
+		"merge": template.Must(template.New("body").Parse(`{{.Title}}
+{{if .ChangeLog}}
+{{range .ChangeLog}}ChangeLog: {{.}}
+{{end}}{{end}}
+Automatically merged due to "{{.Label}}" label
+{{range .Reviewers}}
+Reviewed-by: {{.}}{{end}}`)),
+		"squash": template.Must(template.New("body").Parse(`{{if .ChangeLog}}{{range .ChangeLog}}ChangeLog: {{.}}
+{{end}}
+{{end}}Automatically merged due to "{{.Label}}" label
+{{range .Reviewers}}
+Reviewed-by: {{.}}{{end}}{{range .CoAuthors}}
+Co-authored-by: {{.}}{{end}}`)),
+	}
+)
+
+// mergeData is passed to the title and body templates.
+type mergeData struct {
+	Number    int
+	Title     string
+	Author    string
+	HeadOwner string
+	HeadRef   string
+	HeadSHA   string
+	Label     string
+	ChangeLog []string
+	Reviewers []string
+	CoAuthors []string
+}
+
+// mergeMethod returns the merge method for a pull request in repo carrying
+// labels, and the label that requested it. The boolean return value is false if
+// none of the labels requests an automatic merge.
+func mergeMethod(repo string, labels []string) (string, string, bool) {
+	for _, l := range labels {
+		if l == automergeLabel {
+			if m, ok := mergeMethods[repo]; ok {
+				return m, l, true
+			}
+			return defaultMergeMethod, l, true
+		}
+
+		if !strings.HasPrefix(l, automergeLabel+":") {
+			continue
+		}
+
+		m := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(l, automergeLabel+":")))
+		if validMergeMethods[m] {
+			return m, l, true
+		}
+	}
+
+	return "", "", false
+}
+
+func render(tmpl map[string]*template.Template, method string, data mergeData) (string, error) {
+	t, ok := tmpl[method]
+	if !ok {
+		return "", nil
+	}
+
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("%s template: %w", t.Name(), err)
+	}
+
+	return strings.TrimSpace(b.String()), nil
+}
+
+// newMergeData collects the information available to the title and body
+// templates.
+func newMergeData(ctx context.Context, c *client.Client, pr *client.PR, label string) (mergeData, error) {
+	data := mergeData{
+		Number:    pr.Number(),
+		Title:     pr.GetTitle(),
+		Author:    pr.GetUser().GetLogin(),
+		HeadOwner: pr.GetHead().GetUser().GetLogin(),
+		HeadRef:   pr.GetHead().GetRef(),
+		HeadSHA:   pr.GetHead().GetSHA(),
+		Label:     label,
+	}
+
+	data.ChangeLog = changelog.Entries(pr.GetBody())
+
+	reviews, err := pr.Reviews(ctx)
+	if err != nil {
+		return data, err
+	}
+
+	seen := map[string]bool{}
+	for _, r := range reviews {
+		login := r.GetUser().GetLogin()
+		if r.GetState() != "APPROVED" || seen[login] {
+			continue
+		}
+		seen[login] = true
+		data.Reviewers = append(data.Reviewers, c.FormatUser(ctx, login))
+	}
+
+	commits, err := pr.Commits(ctx)
+	if err != nil {
+		return data, err
+	}
+
+	seen = map[string]bool{}
+	for _, rc := range commits {
+		if rc.GetAuthor().GetLogin() == data.Author {
+			continue
+		}
+
+		a := rc.GetCommit().GetAuthor()
+		if a.GetEmail() == "" {
+			continue
+		}
+
+		coAuthor := fmt.Sprintf("%s <%s>", a.GetName(), a.GetEmail())
+		if seen[coAuthor] {
+			continue
+		}
+		seen[coAuthor] = true
+		data.CoAuthors = append(data.CoAuthors, coAuthor)
+	}
+
+	return data, nil
+}
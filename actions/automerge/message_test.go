@@ -0,0 +1,90 @@
+package automerge
+
+import (
+	"testing"
+)
+
+func TestMergeMethod(t *testing.T) {
+	cases := []struct {
+		labels     []string
+		wantMethod string
+		wantOK     bool
+	}{
+		{[]string{"Fix"}, "", false},
+		{[]string{"Fix", "Automerge"}, "merge", true},
+		{[]string{"Automerge: squash"}, "squash", true},
+		{[]string{"Automerge:rebase"}, "rebase", true},
+		{[]string{"Automerge: Squash"}, "squash", true},
+		{[]string{"Automerge: octopus"}, "", false},
+		{[]string{"automerge"}, "", false},
+	}
+
+	for _, tc := range cases {
+		got, _, gotOK := mergeMethod("collectd/collectd", tc.labels)
+		if got != tc.wantMethod || gotOK != tc.wantOK {
+			t.Errorf("mergeMethod(%q) = (%q, %v), want (%q, %v)", tc.labels, got, gotOK, tc.wantMethod, tc.wantOK)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	data := mergeData{
+		Number:    42,
+		Title:     "Foo plugin: Add bar option.",
+		HeadOwner: "octo",
+		HeadRef:   "ff/foo",
+		Label:     "Automerge: squash",
+		ChangeLog: []string{"Foo plugin: The \"Bar\" option has been added."},
+		Reviewers: []string{"@alice (Alice)"},
+		CoAuthors: []string{"Bob <bob@example.com>"},
+	}
+
+	cases := []struct {
+		method    string
+		wantTitle string
+		wantBody  string
+	}{
+		{
+			method:    "merge",
+			wantTitle: "Auto-Merge pull request #42 from octo/ff/foo",
+			wantBody: `Foo plugin: Add bar option.
+
+ChangeLog: Foo plugin: The "Bar" option has been added.
+
+Automatically merged due to "Automerge: squash" label
+
+Reviewed-by: @alice (Alice)`,
+		},
+		{
+			method:    "squash",
+			wantTitle: "Foo plugin: Add bar option. (#42)",
+			wantBody: `ChangeLog: Foo plugin: The "Bar" option has been added.
+
+Automatically merged due to "Automerge: squash" label
+
+Reviewed-by: @alice (Alice)
+Co-authored-by: Bob <bob@example.com>`,
+		},
+		{
+			method: "rebase",
+		},
+	}
+
+	for _, tc := range cases {
+		title, err := render(titleTemplates, tc.method, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if title != tc.wantTitle {
+			t.Errorf("render(titleTemplates, %q) = %q, want %q", tc.method, title, tc.wantTitle)
+		}
+
+		body, err := render(bodyTemplates, tc.method, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body != tc.wantBody {
+			t.Errorf("render(bodyTemplates, %q) = %q, want %q", tc.method, body, tc.wantBody)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package automerge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/octo/ghbot/client"
+	"github.com/octo/ghbot/config"
+)
+
+const headKind = "AutomergeHead"
+
+// head records when the bot first saw a head commit of a pull request.
+type head struct {
+	SHA       string
+	FirstSeen time.Time
+}
+
+// headUpdated returns the time the head of pr was last updated. Commit dates
+// are set by the author and cannot be trusted, so this is the time the bot
+// first saw the current head commit, usually when handling the "synchronize"
+// event of the push. This is never earlier than the actual push.
+func headUpdated(ctx context.Context, c *client.Client, pr *client.PR) (time.Time, error) {
+	db, err := config.Datastore(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	sha := pr.GetHead().GetSHA()
+	k := datastore.NameKey(headKind, fmt.Sprintf("%s#%d", repoName(c), pr.Number()), nil)
+
+	var h head
+	_, err = db.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		h = head{}
+		if err := tx.Get(k, &h); err != nil && !errors.Is(err, datastore.ErrNoSuchEntity) {
+			return err
+		}
+		if h.SHA == sha {
+			return nil
+		}
+
+		h = head{
+			SHA:       sha,
+			FirstSeen: time.Now(),
+		}
+		_, err := tx.Put(k, &h)
+		return err
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("automerge: head of %v: %w", pr, err)
+	}
+
+	return h.FirstSeen, nil
+}
@@ -0,0 +1,215 @@
+package automerge
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mtraver/gaelog"
+	"github.com/octo/ghbot/client"
+	"github.com/octo/ghbot/mergequeue"
+	"github.com/octo/ghbot/policy"
+)
+
+// headTimeout is how long a pull request may stay at the head of a merge queue
+// without being merged, e.g. while waiting for a required check. After that,
+// it is removed from the queue so that it doesn't block the pull requests
+// behind it.
+var headTimeout = 3 * time.Hour
+
+func repoName(c *client.Client) string {
+	return c.Owner() + "/" + c.Repo()
+}
+
+// dequeue removes pr from the merge queue of its base branch. If pr was the
+// head of the queue, the new head is processed: nothing else would trigger it
+// until it times out.
+func dequeue(ctx context.Context, c *client.Client, pr *client.PR) error {
+	next, err := mergequeue.Remove(ctx, repoName(c), pr.GetBase().GetRef(), pr.Number())
+	if err != nil || next == 0 {
+		return err
+	}
+
+	nextPR, err := c.PR(ctx, next)
+	if err != nil {
+		return err
+	}
+	return process(ctx, c, nextPR)
+}
+
+// processQueue adds pr, which satisfies the merge policy, to the merge queue
+// of its base branch. If pr is at the head of the queue, it is brought up to
+// date with the base branch if necessary, and merged otherwise. Removing the
+// head of the queue, e.g. after merging it, triggers processing of the next
+// pull request.
+func processQueue(ctx context.Context, c *client.Client, pr *client.PR, method, label string, report policy.Report) error {
+	branch := pr.GetBase().GetRef()
+
+	q, err := mergequeue.Enqueue(ctx, repoName(c), branch, pr.Number())
+	if err != nil {
+		return err
+	}
+
+	if pos := q.Position(pr.Number()); pos > 0 {
+		head, err := c.PR(ctx, q.PRs[0])
+		if err != nil {
+			return err
+		}
+
+		// The head of the queue has been closed or merged without us
+		// noticing. Drop it, which processes the new head. That may be
+		// pr, so pr must not be processed again here.
+		if head.GetMerged() || head.GetState() != "open" {
+			return dequeue(ctx, c, head)
+		}
+
+		if timedOut(q) {
+			return evict(ctx, c, head, fmt.Sprintf("it was not merged within %v", headTimeout))
+		}
+
+		gaelog.Debugf(ctx, "automerge: %v is at position %d of the %q merge queue", pr, pos+1, branch)
+		publishCheck(ctx, c, pr, client.ConclusionNeutral,
+			fmt.Sprintf("Queued for merge, position %d of %d (waiting for %v)", pos+1, len(q.PRs), head),
+			report)
+		return nil
+	}
+
+	behind, err := pr.BehindBy(ctx)
+	if err != nil {
+		return err
+	}
+	if behind > 0 {
+		gaelog.Infof(ctx, "automerge: updating %v, which is %d commit(s) behind %q", pr, behind, branch)
+		ok, err := updateBranch(ctx, c, pr)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			publishCheck(ctx, c, pr, client.ConclusionFailure,
+				fmt.Sprintf("Not merging: conflicts with %s", branch),
+				report)
+			return dequeue(ctx, c, pr)
+		}
+
+		publishCheck(ctx, c, pr, client.ConclusionNeutral,
+			fmt.Sprintf("Updating branch, %d commit(s) behind %s", behind, branch),
+			report)
+		return nil
+	}
+
+	if err := merge(ctx, c, pr, method, label, report); err != nil {
+		gaelog.Warningf(ctx, "automerge: %v", err)
+		return evict(ctx, c, pr, fmt.Sprintf("merging failed: %v", err))
+	}
+
+	return dequeue(ctx, c, pr)
+}
+
+func timedOut(q *mergequeue.Queue) bool {
+	return len(q.PRs) != 0 && !q.HeadSince.IsZero() && time.Since(q.HeadSince) > headTimeout
+}
+
+// evict removes pr from the merge queue of its base branch and tells the
+// author why. The pull request is queued again once it satisfies the merge
+// policy.
+func evict(ctx context.Context, c *client.Client, pr *client.PR, reason string) error {
+	gaelog.Infof(ctx, "automerge: removing %v from the merge queue: %s", pr, reason)
+
+	if err := dequeue(ctx, c, pr); err != nil {
+		return err
+	}
+
+	// The check's details are posted as a comment, which tells the author.
+	check := client.Check{
+		Name:       checkName,
+		Conclusion: client.ConclusionFailure,
+		Title:      "Removed from the merge queue",
+		Summary: fmt.Sprintf("This pull request has been removed from the merge queue of `%s`, because %s. "+
+			"It is queued again once it satisfies the merge policy.", pr.GetBase().GetRef(), reason),
+	}
+	return pr.SetCheck(ctx, check)
+}
+
+// processTimeouts evicts pull requests that have been at the head of a merge
+// queue for too long. Pull requests waiting for a check that never reports
+// don't trigger any events, so this runs periodically.
+func processTimeouts(ctx context.Context) error {
+	c, err := client.New(ctx, client.DefaultOwner, client.DefaultRepo)
+	if err != nil {
+		return err
+	}
+
+	queues, err := mergequeue.All(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, q := range queues {
+		if q.Repo != repoName(c) || !timedOut(q) {
+			continue
+		}
+
+		head, err := c.PR(ctx, q.PRs[0])
+		if err != nil {
+			return err
+		}
+		if err := evict(ctx, c, head, fmt.Sprintf("it was not merged within %v", headTimeout)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func merge(ctx context.Context, c *client.Client, pr *client.PR, method, label string, report policy.Report) error {
+	data, err := newMergeData(ctx, c, pr, label)
+	if err != nil {
+		return err
+	}
+
+	title, err := render(titleTemplates, method, data)
+	if err != nil {
+		return err
+	}
+
+	msg, err := render(bodyTemplates, method, data)
+	if err != nil {
+		return err
+	}
+
+	publishCheck(ctx, c, pr, client.ConclusionSuccess, fmt.Sprintf("Merging (%s)", method), report)
+
+	gaelog.Infof(ctx, "merging %v (method %q)", pr, method)
+	return pr.Merge(ctx, method, title, msg)
+}
+
+// processNativeQueue adds pr to Github's merge queue if it satisfies the merge
+// policy. If it only waits for checks, auto-merge is enabled so that Github
+// adds it to the queue once the checks have passed. Pull requests that are
+// already queued, or have auto-merge enabled, are left alone.
+func processNativeQueue(ctx context.Context, c *client.Client, pr *client.PR, state client.MergeQueueState, method string, report policy.Report) error {
+	if state.Position != 0 {
+		gaelog.Debugf(ctx, "automerge: %v is at position %d of the merge queue", pr, state.Position)
+		return nil
+	}
+
+	if report.Pending() {
+		publishCheck(ctx, c, pr, client.ConclusionNeutral, reportTitle(report), report)
+		if state.AutoMerge {
+			return nil
+		}
+		gaelog.Debugf(ctx, "automerge: enabling auto-merge for %v", pr)
+		return pr.EnableAutoMerge(ctx, method)
+	}
+
+	pos, err := pr.Enqueue(ctx)
+	if err != nil {
+		return err
+	}
+
+	gaelog.Infof(ctx, "automerge: added %v to the merge queue at position %d", pr, pos)
+	publishCheck(ctx, c, pr, client.ConclusionSuccess,
+		fmt.Sprintf("Added to the merge queue, position %d", pos),
+		report)
+	return nil
+}
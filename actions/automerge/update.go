@@ -0,0 +1,91 @@
+package automerge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/mtraver/gaelog"
+	"github.com/octo/ghbot/client"
+)
+
+// updateBranch brings pr up to date with its base branch. If this is not
+// possible due to conflicts, a comment listing the conflicting files is added
+// to the pull request and false is returned.
+func updateBranch(ctx context.Context, c *client.Client, pr *client.PR) (bool, error) {
+	err := pr.UpdateBranch(ctx)
+	switch updateError(err) {
+	case updateConflict:
+		files, err := pr.ConflictingFiles(ctx)
+		if err != nil {
+			return false, err
+		}
+		return false, reportConflict(ctx, c, pr, files)
+	case updateHeadMoved:
+		// Someone pushed to the branch concurrently. The push triggers
+		// another event, which takes it from here.
+		gaelog.Infof(ctx, "automerge: head of %v changed while updating it", pr)
+		return true, nil
+	case updateFailed:
+		return false, err
+	}
+
+	return true, nil
+}
+
+const (
+	updateOK = iota
+	updateConflict
+	updateHeadMoved
+	updateFailed
+)
+
+// updateError classifies an error returned by UpdateBranch. Github responds
+// with "422 Unprocessable Entity" both if there are merge conflicts and if the
+// head of the pull request has changed, so the message has to be checked.
+func updateError(err error) int {
+	if err == nil {
+		return updateOK
+	}
+
+	var errRes *github.ErrorResponse
+	if !errors.As(err, &errRes) || errRes.Response == nil || errRes.Response.StatusCode != http.StatusUnprocessableEntity {
+		return updateFailed
+	}
+
+	msg := strings.ToLower(errRes.Message)
+	switch {
+	case strings.Contains(msg, "merge conflict"):
+		return updateConflict
+	case strings.Contains(msg, "expected head sha"):
+		return updateHeadMoved
+	default:
+		return updateFailed
+	}
+}
+
+// reportConflict adds a sticky comment listing files to pr, or updates it.
+func reportConflict(ctx context.Context, c *client.Client, pr *client.PR, files []string) error {
+	gaelog.Infof(ctx, "automerge: %v has conflicts with %q", pr, pr.GetBase().GetRef())
+
+	issue, err := pr.Issue(ctx)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "This pull request cannot be merged automatically, because it conflicts with `%s`.\n", pr.GetBase().GetRef())
+	if len(files) != 0 {
+		fmt.Fprintln(&b, "\nThe following files have been changed on both branches:")
+		fmt.Fprintln(&b)
+		for _, f := range files {
+			fmt.Fprintf(&b, "* `%s`\n", f)
+		}
+	}
+	fmt.Fprintf(&b, "\nPlease rebase your branch onto `%s` and resolve the conflicts.\n", pr.GetBase().GetRef())
+
+	return issue.SetStickyComment(ctx, "automerge-conflict", b.String())
+}
@@ -0,0 +1,41 @@
+package automerge
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestUpdateError(t *testing.T) {
+	errorResponse := func(code int, msg string) error {
+		return fmt.Errorf("UpdateBranch(#1): %w", &github.ErrorResponse{
+			Response: &http.Response{
+				StatusCode: code,
+				Request:    &http.Request{Method: http.MethodPut, URL: &url.URL{Path: "/update-branch"}},
+			},
+			Message: msg,
+		})
+	}
+
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{nil, updateOK},
+		{errorResponse(http.StatusUnprocessableEntity, "merge conflict between base and head"), updateConflict},
+		{errorResponse(http.StatusUnprocessableEntity, "expected head sha didn't match current head ref."), updateHeadMoved},
+		{errorResponse(http.StatusUnprocessableEntity, "Validation Failed"), updateFailed},
+		{errorResponse(http.StatusForbidden, "merge conflict between base and head"), updateFailed},
+		{errors.New("connection reset"), updateFailed},
+	}
+
+	for _, tc := range cases {
+		if got := updateError(tc.err); got != tc.want {
+			t.Errorf("updateError(%v) = %d, want %d", tc.err, got, tc.want)
+		}
+	}
+}
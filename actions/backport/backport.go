@@ -0,0 +1,216 @@
+// Package backport backports merged pull requests to release branches.
+//
+// Maintainers request a backport by setting a label of the form
+// "backport collectd-<major>.<minor>" on a pull request. Once the pull request
+// is merged, its commits are cherry-picked onto a new branch off the release
+// branch and a pull request for the release branch is opened. If the commits
+// don't apply cleanly, the conflicting files are reported in a comment and the
+// backport has to be done manually.
+package backport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/mtraver/gaelog"
+	"github.com/octo/ghbot/actions/changelog"
+	"github.com/octo/ghbot/client"
+	"github.com/octo/ghbot/event"
+)
+
+var labelRE = regexp.MustCompile(`^(?i:backport)\s+(collectd-[0-9]+\.[0-9]+)$`)
+
+// copyLabels are the labels copied from the original pull request to the
+// backport.
+var copyLabels = []string{"Feature", "Fix", "Maintenance"}
+
+func init() {
+	event.PullRequestHandler("backport", handler)
+}
+
+// releaseBranch returns the release branch a backport label refers to. The
+// boolean return value is false if label is not a backport label.
+func releaseBranch(label string) (string, bool) {
+	m := labelRE.FindStringSubmatch(strings.TrimSpace(label))
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// backportBranch returns the name of the branch holding the backport of the
+// pull request number to the release branch.
+func backportBranch(release string, number int) string {
+	return fmt.Sprintf("backport/%s/pr-%d", release, number)
+}
+
+func handler(ctx context.Context, e *github.PullRequestEvent) error {
+	if !e.GetPullRequest().GetMerged() {
+		return nil
+	}
+
+	var labels []string
+	switch e.GetAction() {
+	case "closed":
+		for _, l := range e.GetPullRequest().Labels {
+			labels = append(labels, l.GetName())
+		}
+	case "labeled":
+		labels = []string{e.GetLabel().GetName()}
+	default:
+		return nil
+	}
+
+	c, err := client.New(ctx, client.DefaultOwner, client.DefaultRepo)
+	if err != nil {
+		return err
+	}
+
+	pr := c.WrapPR(e.GetPullRequest())
+	for _, l := range labels {
+		release, ok := releaseBranch(l)
+		if !ok {
+			continue
+		}
+
+		if err := backport(ctx, c, pr, release); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// errNoBranch is returned when the release branch does not exist.
+var errNoBranch = errors.New("the branch does not exist")
+
+// backport backports pr to the release branch. The result is reported in a
+// comment on pr, including failures.
+func backport(ctx context.Context, c *client.Client, pr *client.PR, release string) error {
+	branch := backportBranch(release, pr.Number())
+
+	existing, err := c.PRByHead(ctx, release, branch)
+	if err == nil {
+		gaelog.Infof(ctx, "backport: %v already backports %v to %q, skipping", existing, pr, release)
+		return nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	orig, err := pr.Issue(ctx)
+	if err != nil {
+		return err
+	}
+
+	bp, err := createBackport(ctx, c, pr, orig, release, branch)
+	var cerr *client.ConflictError
+	switch {
+	case err == nil:
+		return orig.Comment(ctx, fmt.Sprintf("Backported to `%s` in %v.", release, bp))
+	case errors.As(err, &cerr):
+		gaelog.Infof(ctx, "backport: %v does not apply to %q: %v", pr, release, err)
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "Unable to backport to `%s`: the following files have been changed on the release branch, too:\n\n", release)
+		for _, f := range cerr.Files {
+			fmt.Fprintf(&b, "* `%s`\n", f)
+		}
+		fmt.Fprintf(&b, "\nPlease backport this change manually.")
+		return orig.Comment(ctx, b.String())
+	case errors.Is(err, errNoBranch):
+		return orig.Comment(ctx, fmt.Sprintf("Unable to backport to `%s`: %v.", release, err))
+	default:
+		gaelog.Errorf(ctx, "backport: backporting %v to %q: %v", pr, release, err)
+		if cerr := orig.Comment(ctx, fmt.Sprintf("Unable to backport to `%s`: %v\n\nPlease backport this change manually.", release, err)); cerr != nil {
+			gaelog.Warningf(ctx, "backport: %v", cerr)
+		}
+		return err
+	}
+}
+
+// createBackport cherry-picks the commits of pr onto branch, a new branch off
+// release, and opens a pull request for it. If branch already exists, it is
+// left over from an attempt that failed to open the pull request, and is used
+// as is.
+func createBackport(ctx context.Context, c *client.Client, pr *client.PR, orig *client.Issue, release, branch string) (*client.PR, error) {
+	_, err := c.BranchSHA(ctx, branch)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if err := createBranch(ctx, c, pr, release, branch); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		gaelog.Infof(ctx, "backport: branch %q already exists, opening a pull request for it", branch)
+	}
+
+	body := fmt.Sprintf("Backport of %v to `%s`.", pr, release)
+	if entries := changelog.Entries(pr.GetBody()); len(entries) != 0 {
+		body += "\n"
+		for _, e := range entries {
+			body += "\nChangeLog: " + e
+		}
+	}
+
+	bp, err := c.CreatePR(ctx, release, branch, fmt.Sprintf("[%s] %s", release, pr.GetTitle()), body)
+	if err != nil {
+		return nil, err
+	}
+	gaelog.Infof(ctx, "backport: created %v, backporting %v to %q", bp, pr, release)
+
+	bpIssue, err := bp.Issue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range copyLabels {
+		if !orig.HasLabel(l) {
+			continue
+		}
+		if err := bpIssue.AddLabel(ctx, l); err != nil {
+			gaelog.Warningf(ctx, "backport: %v", err)
+		}
+	}
+
+	return bp, nil
+}
+
+// createBranch cherry-picks the commits of pr onto branch, a new branch off
+// release.
+func createBranch(ctx context.Context, c *client.Client, pr *client.PR, release, branch string) error {
+	onto, err := c.BranchSHA(ctx, release)
+	if errors.Is(err, os.ErrNotExist) {
+		return errNoBranch
+	}
+	if err != nil {
+		return err
+	}
+
+	commits, err := pr.Commits(ctx)
+	if err != nil {
+		return err
+	}
+
+	var shas []string
+	for _, rc := range commits {
+		// Merge commits, e.g. from updating the branch, bring in changes
+		// of the base branch, which must not be backported.
+		if len(rc.Parents) > 1 {
+			continue
+		}
+		shas = append(shas, rc.GetSHA())
+	}
+
+	head, err := c.CherryPick(ctx, shas, onto)
+	if err != nil {
+		return err
+	}
+
+	return c.CreateBranch(ctx, branch, head)
+}
@@ -0,0 +1,26 @@
+package backport
+
+import "testing"
+
+func TestReleaseBranch(t *testing.T) {
+	cases := []struct {
+		label  string
+		want   string
+		wantOK bool
+	}{
+		{"backport collectd-5.12", "collectd-5.12", true},
+		{"Backport collectd-6.0", "collectd-6.0", true},
+		{"backport  collectd-5.12 ", "collectd-5.12", true},
+		{"backport main", "", false},
+		{"backport collectd-5", "", false},
+		{"collectd-5.12", "", false},
+		{"Feature", "", false},
+	}
+
+	for _, tc := range cases {
+		got, ok := releaseBranch(tc.label)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("releaseBranch(%q) = (%q, %v), want (%q, %v)", tc.label, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
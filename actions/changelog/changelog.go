@@ -2,7 +2,9 @@
 //
 // Users may add `ChangeLog: [text]` to the pull request description. Text is a
 // single change log entry along the lines of "Foo plugin: Implemented a
-// thing.".
+// thing.". Multiple "ChangeLog:" lines add multiple entries. Entries are
+// checked against lintRules, e.g. that "Foo" is a plugin in src/ and that the
+// entry ends with a period.
 //
 // For trivial changes, maintainers may set the "Maintenance" label which
 // allows submission without change log information. If both are present, text
@@ -17,6 +19,7 @@ import (
 	"strings"
 
 	"github.com/google/go-github/github"
+	"github.com/octo/ghbot/actions/labelsync"
 	"github.com/octo/ghbot/client"
 	"github.com/octo/ghbot/event"
 )
@@ -33,16 +36,46 @@ var (
 
 func init() {
 	event.PullRequestHandler("changelog", handler)
+	event.MergeGroupHandler("changelog", client.MergeGroupHandler(process))
+	labelsync.Require("changelog", labelMaintenance)
 }
 
-func formatEntry(ctx context.Context, c *client.Client, pr *client.PR) (string, bool) {
-	m := logEntryRE.FindStringSubmatch(pr.GetBody())
-	if len(m) < 2 {
+// Entry returns the change log entry contained in a pull request description,
+// i.e. the text following "ChangeLog:". The boolean return value is false if
+// body does not contain a change log entry. If body contains multiple
+// entries, the first one is returned.
+func Entry(body string) (string, bool) {
+	entries := Entries(body)
+	if len(entries) == 0 {
 		return "", false
 	}
-	msg := strings.TrimSpace(m[1])
 
-	return fmt.Sprintf("%s Thanks to %s. %v", msg, c.FormatUser(ctx, pr.GetUser().GetLogin()), pr), true
+	return entries[0], true
+}
+
+// Entries returns all change log entries contained in a pull request
+// description, one per "ChangeLog:" line.
+func Entries(body string) []string {
+	var ret []string
+	for _, m := range logEntryRE.FindAllStringSubmatch(body, -1) {
+		ret = append(ret, strings.TrimSpace(m[1]))
+	}
+	return ret
+}
+
+// formatEntries returns the change log entries of pr, attributed to the
+// pull request's author.
+func formatEntries(ctx context.Context, c *client.Client, pr *client.PR) []string {
+	entries := Entries(pr.GetBody())
+	if len(entries) == 0 {
+		return nil
+	}
+
+	user := c.FormatUser(ctx, pr.GetUser().GetLogin())
+	for i, e := range entries {
+		entries[i] = fmt.Sprintf("%s Thanks to %s. %v", e, user, pr)
+	}
+	return entries
 }
 
 func handler(ctx context.Context, e *github.PullRequestEvent) error {
@@ -63,7 +96,11 @@ func handler(ctx context.Context, e *github.PullRequestEvent) error {
 	}
 
 	pr := c.WrapPR(e.PullRequest)
-	ref := pr.Head.GetSHA()
+	return process(ctx, c, pr, pr.Head.GetSHA())
+}
+
+// process sets the ChangeLog status of pr on the commit ref.
+func process(ctx context.Context, c *client.Client, pr *client.PR, ref string) error {
 	log.Println("checking if", pr, "contains a changelog note")
 
 	// Only issues report the label :(
@@ -73,13 +110,59 @@ func handler(ctx context.Context, e *github.PullRequestEvent) error {
 	}
 
 	if i.HasLabel(labelMaintenance) {
+		updateSummary(ctx, i, nil)
 		return c.CreateStatus(ctx, checkName, client.StatusSuccess, "Pull request not included in ChangeLog", detailsURL, ref)
 	}
 
-	if entry, ok := formatEntry(ctx, c, pr); ok {
-		msg := fmt.Sprintf("Preview: %q", entry)
-		return c.CreateStatus(ctx, checkName, client.StatusSuccess, msg, detailsURL, ref)
+	entries := Entries(pr.GetBody())
+	if len(entries) == 0 {
+		const msg = `Please add a "ChangeLog: …" line to your pull request description`
+		updateSummary(ctx, i, []string{msg})
+		return c.CreateStatus(ctx, checkName, client.StatusFailure, msg, detailsURL, ref)
 	}
 
-	return c.CreateStatus(ctx, checkName, client.StatusFailure, `Please add a "ChangeLog: …" line to your pull request description`, detailsURL, ref)
+	plugins, err := plugins(ctx, c, ref)
+	if err != nil {
+		// Skip the component check rather than blocking the pull request.
+		log.Print(err)
+	}
+
+	var problems []string
+	for _, e := range entries {
+		problems = append(problems, lintRules.check(e, plugins)...)
+	}
+	updateSummary(ctx, i, problems)
+	if len(problems) != 0 {
+		msg := problems[0]
+		if len(problems) > 1 {
+			msg = fmt.Sprintf("%s (and %d more problems)", msg, len(problems)-1)
+		}
+		return c.CreateStatus(ctx, checkName, client.StatusFailure, msg, detailsURL, ref)
+	}
+
+	formatted := formatEntries(ctx, c, pr)
+	msg := fmt.Sprintf("Preview: %q", formatted[0])
+	if len(formatted) > 1 {
+		msg = fmt.Sprintf("Preview: %q (and %d more entries)", formatted[0], len(formatted)-1)
+	}
+	return c.CreateStatus(ctx, checkName, client.StatusSuccess, msg, detailsURL, ref)
+}
+
+// updateSummary maintains a sticky comment listing all problems, because the
+// status description only has room for the first one. The comment is removed
+// once all problems are fixed.
+func updateSummary(ctx context.Context, i *client.Issue, problems []string) {
+	var b strings.Builder
+	if len(problems) != 0 {
+		fmt.Fprintln(&b, "The ChangeLog information of this pull request needs some changes:")
+		fmt.Fprintln(&b)
+		for _, p := range problems {
+			fmt.Fprintf(&b, "* %s\n", p)
+		}
+		fmt.Fprintf(&b, "\nSee the [contributing guidelines](%s) for details. This comment is updated when you edit the pull request.\n", detailsURL)
+	}
+
+	if err := i.SetStickyComment(ctx, "changelog", b.String()); err != nil {
+		log.Printf("changelog: %v", err)
+	}
 }
@@ -1,7 +1,9 @@
 package changelog
 
 import (
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestRegexp(t *testing.T) {
@@ -28,3 +30,117 @@ func TestRegexp(t *testing.T) {
 		}
 	}
 }
+
+func TestPRNumber(t *testing.T) {
+	cases := []struct {
+		msg    string
+		want   int
+		wantOK bool
+	}{
+		{"Merge pull request #1234 from octo/feature\n\nFoo plugin: Bar.", 1234, true},
+		{"Auto-Merge pull request #42 from user/branch", 42, true},
+		{"Foo plugin: Fix a thing. (#4321)\n\nChangeLog: Foo plugin: Fixed a thing.", 4321, true},
+		{"Foo plugin: Fix a thing.", 0, false},
+		{"Fix #123 in the foo plugin", 0, false},
+	}
+
+	for _, tc := range cases {
+		got, ok := prNumber(tc.msg)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("prNumber(%q) = (%d, %v), want (%d, %v)", tc.msg, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestNotes(t *testing.T) {
+	n := &notes{
+		features: []string{"Foo plugin: New option. Thanks to @a. #1"},
+		fixes:    []string{"Bar plugin: Fixed crash. Thanks to @b. #2"},
+		missing:  []string{"#3"},
+	}
+
+	wantMarkdown := `### New features
+
+* Foo plugin: New option. Thanks to @a. #1
+
+### Bug fixes
+
+* Bar plugin: Fixed crash. Thanks to @b. #2
+
+Pull requests without ChangeLog entry: #3
+`
+	if got := n.Markdown(); got != wantMarkdown {
+		t.Errorf("Markdown() = %q, want %q", got, wantMarkdown)
+	}
+
+	wantChangeLog := "2021-03-04, Version 5.13.0\n" +
+		"\t* Foo plugin: New option. Thanks to @a. #1\n" +
+		"\t* Bar plugin: Fixed crash. Thanks to @b. #2\n"
+	if got := n.ChangeLog("5.13.0", time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)); got != wantChangeLog {
+		t.Errorf("ChangeLog() = %q, want %q", got, wantChangeLog)
+	}
+}
+
+func TestEntries(t *testing.T) {
+	body := "Summary\n\nChangeLog: Foo plugin: Added a thing.\r\nchangelog: Bar plugin: Fixed a thing.\n"
+	want := []string{"Foo plugin: Added a thing.", "Bar plugin: Fixed a thing."}
+	if got := Entries(body); !reflect.DeepEqual(got, want) {
+		t.Errorf("Entries(%q) = %q, want %q", body, got, want)
+	}
+}
+
+func TestLint(t *testing.T) {
+	plugins := map[string]bool{"cpu": true, "memory": true, "write_http": true}
+
+	cases := []struct {
+		entry string
+		// want is the number of problems found.
+		want int
+	}{
+		{"CPU plugin: Added the \"ReportByState\" option.", 0},
+		{"Write HTTP plugin: Fixed a memory leak.", 0},
+		{"CPU, Memory plugins: Fixed a memory leak.", 0},
+		{"CPU and memory plugins: Fixed a memory leak.", 0},
+		{"collectd: Fixed a race condition.", 0},
+		{"Build system: Fixed a race condition.", 0},
+		{"Nonexistent plugin: Fixed a memory leak.", 1},
+		{"CPU plugin: fixed a memory leak.", 1},
+		{"CPU plugin: Fixed a memory leak", 1},
+		{"Fixed a memory leak.", 1},
+		{"Something: Fixed a memory leak.", 1},
+		{"CPU plugin: x.", 1},
+		{"Foo plugin: Implemented a thing.", 2},
+	}
+
+	for _, tc := range cases {
+		got := lintRules.check(tc.entry, plugins)
+		if len(got) != tc.want {
+			t.Errorf("check(%q) = %q, want %d problems", tc.entry, got, tc.want)
+		}
+	}
+
+	// Without a list of plugins, the component is not verified.
+	if got := lintRules.check("Nonexistent plugin: Fixed a memory leak.", nil); len(got) != 0 {
+		t.Errorf("check() = %q, want no problems", got)
+	}
+}
+
+func TestVersionRE(t *testing.T) {
+	cases := []struct {
+		title string
+		want  bool
+	}{
+		{"5.12", true},
+		{"5.12.1", true},
+		{"6.0.0", true},
+		{"Features", false},
+		{"5", false},
+		{"5.12-rc1", false},
+	}
+
+	for _, tc := range cases {
+		if got := versionRE.MatchString(tc.title); got != tc.want {
+			t.Errorf("versionRE.MatchString(%q) = %v, want %v", tc.title, got, tc.want)
+		}
+	}
+}
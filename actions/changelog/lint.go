@@ -0,0 +1,142 @@
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/octo/ghbot/client"
+)
+
+// rules configures the validation of ChangeLog entries.
+type rules struct {
+	// components are accepted as entry prefix in addition to
+	// "<plugin> plugin:" and "<plugin>, <plugin> plugins:".
+	components []string
+	// capitalized requires the text following the prefix to start with an
+	// upper case letter.
+	capitalized bool
+	// period requires entries to end with a period.
+	period bool
+	// minLength and maxLength limit the length of the entry in characters.
+	// Zero disables the limit.
+	minLength, maxLength int
+	// templateText is text from the pull request template or documentation
+	// that must not appear in entries.
+	templateText []string
+}
+
+var lintRules = rules{
+	components:   []string{"collectd", "Build system", "Documentation", "Plugin API", "Collectd 6"},
+	capitalized:  true,
+	period:       true,
+	minLength:    10,
+	maxLength:    300,
+	templateText: []string{"Foo plugin: Implemented a thing", "[text]", "TODO"},
+}
+
+// pluginSourceDir is the directory containing one file or directory per
+// plugin.
+const pluginSourceDir = "src"
+
+var (
+	prefixRE  = regexp.MustCompile(`^([^:]+):\s*(.*)$`)
+	pluginsRE = regexp.MustCompile(`^(.+?)\s+plugins?$`)
+	listSepRE = regexp.MustCompile(`\s*(?:,|\band\b)\s*`)
+)
+
+// plugins returns the known plugin names, i.e. the entries of the source
+// directory at ref without file extension.
+func plugins(ctx context.Context, c *client.Client, ref string) (map[string]bool, error) {
+	names, err := c.ListDir(ctx, pluginSourceDir, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := map[string]bool{}
+	for _, n := range names {
+		ret[strings.TrimSuffix(n, path.Ext(n))] = true
+	}
+	return ret, nil
+}
+
+// normalizePlugin converts a plugin name as written in a ChangeLog entry, e.g.
+// "Write HTTP", to the name of its source, e.g. "write_http".
+func normalizePlugin(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+}
+
+// check returns a list of problems with entry. If plugins is nil, the
+// component of entries referring to plugins is not verified.
+func (r rules) check(entry string, plugins map[string]bool) []string {
+	var problems []string
+	quoted := fmt.Sprintf("%q", entry)
+	if n := utf8.RuneCountInString(entry); n > 20 {
+		quoted = fmt.Sprintf("%q", string([]rune(entry)[:19])+"…")
+	}
+
+	for _, t := range r.templateText {
+		if strings.Contains(entry, t) {
+			problems = append(problems, fmt.Sprintf("%s: replace the example text %q with a description of your change", quoted, t))
+		}
+	}
+
+	n := utf8.RuneCountInString(entry)
+	if r.minLength > 0 && n < r.minLength {
+		problems = append(problems, fmt.Sprintf("%s: entry is too short, use at least %d characters", quoted, r.minLength))
+	}
+	if r.maxLength > 0 && n > r.maxLength {
+		problems = append(problems, fmt.Sprintf("%s: entry is too long, use at most %d characters", quoted, r.maxLength))
+	}
+
+	m := prefixRE.FindStringSubmatch(entry)
+	if m == nil {
+		problems = append(problems, fmt.Sprintf(`%s: start the entry with the affected component, e.g. "Foo plugin: "`, quoted))
+		m = []string{entry, "", entry}
+	} else if p := r.checkComponent(m[1], plugins); p != "" {
+		problems = append(problems, fmt.Sprintf("%s: %s", quoted, p))
+	}
+
+	text := m[2]
+	if first, _ := utf8.DecodeRuneInString(text); r.capitalized && unicode.IsLower(first) {
+		problems = append(problems, fmt.Sprintf("%s: start the description with an upper case letter", quoted))
+	}
+	if r.period && !strings.HasSuffix(text, ".") {
+		problems = append(problems, fmt.Sprintf("%s: end the entry with a period", quoted))
+	}
+
+	return problems
+}
+
+// checkComponent returns a problem description if component is neither a
+// known component nor refers to known plugins.
+func (r rules) checkComponent(component string, plugins map[string]bool) string {
+	for _, c := range r.components {
+		if strings.EqualFold(component, c) {
+			return ""
+		}
+	}
+
+	m := pluginsRE.FindStringSubmatch(component)
+	if m == nil {
+		return fmt.Sprintf(`unknown component %q, use "<name> plugin" or one of %s`, component, strings.Join(r.components, ", "))
+	}
+	if plugins == nil {
+		return ""
+	}
+
+	for _, name := range listSepRE.Split(m[1], -1) {
+		if name == "" {
+			continue
+		}
+		if !plugins[normalizePlugin(name)] {
+			return fmt.Sprintf("no plugin called %q in %s/", name, pluginSourceDir)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,151 @@
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/octo/ghbot/client"
+)
+
+const (
+	labelFeature = "Feature"
+	labelFix     = "Fix"
+)
+
+// prNumberRE matches the first line of merge and squash commits created for
+// pull requests, e.g. "Merge pull request #1234 from …" or "Title (#1234)".
+var prNumberRE = regexp.MustCompile(`(?i:merge pull request) #([1-9][0-9]*)|\(#([1-9][0-9]*)\)$`)
+
+// notes are the ChangeLog entries of a set of pull requests, grouped by type.
+type notes struct {
+	features []string
+	fixes    []string
+	other    []string
+	// missing holds pull requests that have neither a ChangeLog entry nor
+	// the "Maintenance" label.
+	missing []string
+}
+
+// collectNotes gathers the ChangeLog entries of prs. Pull requests with the
+// "Maintenance" label are skipped.
+func collectNotes(ctx context.Context, c *client.Client, prs []*client.PR) *notes {
+	var n notes
+	for _, pr := range prs {
+		labels := map[string]bool{}
+		for _, l := range pr.Labels {
+			labels[l.GetName()] = true
+		}
+		if labels[labelMaintenance] {
+			continue
+		}
+
+		entries := formatEntries(ctx, c, pr)
+		switch {
+		case len(entries) == 0:
+			n.missing = append(n.missing, pr.String())
+		case labels[labelFeature]:
+			n.features = append(n.features, entries...)
+		case labels[labelFix]:
+			n.fixes = append(n.fixes, entries...)
+		default:
+			n.other = append(n.other, entries...)
+		}
+	}
+	return &n
+}
+
+// Markdown formats the notes as release description.
+func (n *notes) Markdown() string {
+	var b strings.Builder
+
+	section := func(title string, entries []string) {
+		if len(entries) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "### %s\n\n", title)
+		for _, e := range entries {
+			fmt.Fprintf(&b, "* %s\n", e)
+		}
+		fmt.Fprintln(&b)
+	}
+	section("New features", n.features)
+	section("Bug fixes", n.fixes)
+	section("Other changes", n.other)
+
+	if len(n.missing) != 0 {
+		fmt.Fprintf(&b, "Pull requests without ChangeLog entry: %s\n", strings.Join(n.missing, ", "))
+	}
+
+	return strings.TrimSpace(b.String()) + "\n"
+}
+
+// ChangeLog formats the notes as an entry of collectd's ChangeLog file.
+// Features are listed first, followed by fixes and other changes.
+func (n *notes) ChangeLog(version string, date time.Time) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s, Version %s\n", date.Format("2006-01-02"), version)
+	for _, entries := range [][]string{n.features, n.fixes, n.other} {
+		for _, e := range entries {
+			fmt.Fprintf(&b, "\t* %s\n", e)
+		}
+	}
+
+	return b.String()
+}
+
+// rangePRs returns the pull requests merged between the commits base and
+// head. Pull requests are identified by the messages of their merge commits,
+// so changes merged by rebasing are not found.
+func rangePRs(ctx context.Context, c *client.Client, base, head string) ([]*client.PR, error) {
+	commits, err := c.CommitsBetween(ctx, base, head)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ret  []*client.PR
+		seen = map[int]bool{}
+	)
+	for _, rc := range commits {
+		number, ok := prNumber(rc.GetCommit().GetMessage())
+		if !ok || seen[number] {
+			continue
+		}
+		seen[number] = true
+
+		pr, err := c.PR(ctx, number)
+		if err != nil {
+			return nil, err
+		}
+		if pr.GetMerged() {
+			ret = append(ret, pr)
+		}
+	}
+
+	return ret, nil
+}
+
+// prNumber returns the number of the pull request a merge or squash commit was
+// created for.
+func prNumber(message string) (int, bool) {
+	subject, _, _ := strings.Cut(message, "\n")
+	m := prNumberRE.FindStringSubmatch(strings.TrimSpace(subject))
+	if m == nil {
+		return 0, false
+	}
+
+	s := m[1]
+	if s == "" {
+		s = m[2]
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,240 @@
+package changelog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/mtraver/gaelog"
+	"github.com/octo/ghbot/client"
+	"github.com/octo/ghbot/command"
+	"github.com/octo/ghbot/event"
+)
+
+const (
+	// changeLogFile is the path of the ChangeLog file in the repository.
+	changeLogFile = "ChangeLog"
+	// defaultBranch is the branch ChangeLog updates are proposed for.
+	defaultBranch = "main"
+	// tagPrefix is prepended to milestone titles to get the release tag.
+	tagPrefix = "collectd-"
+)
+
+// versionRE matches milestone titles that are versions, e.g. "5.12.1". Only
+// these are released; other milestones, e.g. "Features", are not.
+var versionRE = regexp.MustCompile(`^[0-9]+(\.[0-9]+)+$`)
+
+func init() {
+	command.Register(command.Command{
+		Name:       "release-notes",
+		Usage:      "<milestone>|<tag>..<tag> [draft|changelog]",
+		Help:       "Collects the ChangeLog entries of merged pull requests. Optionally creates a draft release or a pull request updating the ChangeLog file.",
+		Permission: command.PermissionWrite,
+		Run:        processCommand,
+	})
+	event.MilestoneHandler("changelog", processMilestone)
+	event.ReleaseHandler("changelog", processRelease)
+}
+
+// processCommand handles "/ghbot release-notes".
+func processCommand(ctx context.Context, req *command.Request) (string, error) {
+	if len(req.Args) < 1 || len(req.Args) > 2 {
+		return "", errors.New("usage: release-notes <milestone>|<tag>..<tag> [draft|changelog]")
+	}
+	c := req.Client
+
+	var (
+		prs     []*client.PR
+		version string
+		err     error
+	)
+	if base, head, ok := strings.Cut(req.Args[0], ".."); ok {
+		prs, err = rangePRs(ctx, c, base, head)
+		version = strings.TrimPrefix(head, tagPrefix)
+	} else {
+		var id int
+		id, err = c.MilestoneByTitle(ctx, req.Args[0])
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("no milestone called %q", req.Args[0])
+		}
+		if err == nil {
+			prs, err = c.MilestonePRs(ctx, id)
+		}
+		version = req.Args[0]
+	}
+	if err != nil {
+		return "", err
+	}
+
+	n := collectNotes(ctx, c, prs)
+
+	if len(req.Args) == 1 {
+		return fmt.Sprintf("Release notes for %s:\n\n%s", version, n.Markdown()), nil
+	}
+	if !versionRE.MatchString(version) {
+		return "", fmt.Errorf("%q is not a version", version)
+	}
+
+	switch req.Args[1] {
+	case "draft":
+		rel, err := draftRelease(ctx, c, version, n)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Drafted release %s.", rel.GetHTMLURL()), nil
+	case "changelog":
+		pr, err := proposeChangeLog(ctx, c, version, n)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Opened %v to update the ChangeLog.", pr), nil
+	default:
+		return "", fmt.Errorf("unknown output %q, want \"draft\" or \"changelog\"", req.Args[1])
+	}
+}
+
+// processMilestone creates or updates a draft release when a milestone is
+// closed.
+func processMilestone(ctx context.Context, e *github.MilestoneEvent) error {
+	if e.GetAction() != "closed" {
+		return nil
+	}
+
+	m := e.GetMilestone()
+	if !versionRE.MatchString(m.GetTitle()) {
+		gaelog.Debugf(ctx, "changelog: milestone %q is not a version, not creating a release", m.GetTitle())
+		return nil
+	}
+
+	c, err := client.New(ctx, client.DefaultOwner, client.DefaultRepo)
+	if err != nil {
+		return err
+	}
+
+	prs, err := c.MilestonePRs(ctx, m.GetNumber())
+	if err != nil {
+		return err
+	}
+
+	// Milestones may be closed more than once, e.g. after reopening them,
+	// and webhooks may be redelivered, so an existing draft is updated.
+	rel, err := draftRelease(ctx, c, m.GetTitle(), collectNotes(ctx, c, prs))
+	if errors.Is(err, errPublished) {
+		gaelog.Infof(ctx, "changelog: not updating release for milestone %q: %v", m.GetTitle(), err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	gaelog.Infof(ctx, "changelog: drafted release %s for milestone %q", rel.GetHTMLURL(), m.GetTitle())
+	return nil
+}
+
+// errPublished is returned by draftRelease if the release has already been
+// published.
+var errPublished = errors.New("the release has already been published")
+
+// draftRelease creates a draft release for version with the notes n. If a
+// draft for version exists, its notes are replaced instead.
+func draftRelease(ctx context.Context, c *client.Client, version string, n *notes) (*github.RepositoryRelease, error) {
+	tag := tagPrefix + version
+
+	rel, err := c.ReleaseByTag(ctx, tag)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return c.CreateDraftRelease(ctx, tag, "collectd "+version, n.Markdown())
+	case err != nil:
+		return nil, err
+	case !rel.GetDraft():
+		return nil, errPublished
+	}
+
+	if err := c.SetReleaseBody(ctx, rel.GetID(), n.Markdown()); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// processRelease fills in the description of newly created releases that
+// don't have one, using the pull requests merged since the previous release.
+func processRelease(ctx context.Context, e *github.ReleaseEvent) error {
+	rel := e.GetRelease()
+	if e.GetAction() != "created" || strings.TrimSpace(rel.GetBody()) != "" {
+		return nil
+	}
+
+	c, err := client.New(ctx, client.DefaultOwner, client.DefaultRepo)
+	if err != nil {
+		return err
+	}
+
+	prev, err := c.PreviousRelease(ctx, rel.GetID())
+	if errors.Is(err, os.ErrNotExist) {
+		gaelog.Infof(ctx, "changelog: no release before %q, not generating release notes", rel.GetTagName())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// The tag of a draft release may not exist yet.
+	head := rel.GetTagName()
+	if rel.GetDraft() && rel.GetTargetCommitish() != "" {
+		head = rel.GetTargetCommitish()
+	}
+
+	prs, err := rangePRs(ctx, c, prev.GetTagName(), head)
+	if err != nil {
+		return err
+	}
+
+	return c.SetReleaseBody(ctx, rel.GetID(), collectNotes(ctx, c, prs).Markdown())
+}
+
+// proposeChangeLog opens a pull request adding the notes to the ChangeLog
+// file.
+func proposeChangeLog(ctx context.Context, c *client.Client, version string, n *notes) (*client.PR, error) {
+	parent, err := c.BranchSHA(ctx, defaultBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := c.FileContent(ctx, changeLogFile, parent)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	content = n.ChangeLog(version, time.Now()) + "\n" + content
+
+	sha, err := c.CommitFile(ctx, parent, changeLogFile, content, "ChangeLog: Add entries for version "+version+".")
+	if err != nil {
+		return nil, err
+	}
+
+	branch := "changelog/" + version
+	if err := c.CreateBranch(ctx, branch, sha); err != nil {
+		return nil, err
+	}
+
+	pr, err := c.CreatePR(ctx, defaultBranch, branch, "ChangeLog: Version "+version,
+		"Adds the ChangeLog entries of the pull requests merged for version "+version+".")
+	if err != nil {
+		return nil, err
+	}
+
+	// The ChangeLog update itself does not need a ChangeLog entry.
+	i, err := pr.Issue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.AddLabel(ctx, labelMaintenance); err != nil {
+		gaelog.Warningf(ctx, "changelog: %v", err)
+	}
+
+	return pr, nil
+}
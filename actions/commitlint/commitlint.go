@@ -0,0 +1,152 @@
+// Package commitlint checks that pull request titles and commit messages
+// follow the Conventional Commits format, e.g. "fix(cpu): Handle overflows.".
+//
+// Violations are reported in the "Conventional Commits" check, listing each
+// offending commit. The default automerge policy ignores this check, so it
+// doesn't block merging.
+package commitlint
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/go-github/github"
+	"github.com/octo/ghbot/client"
+	"github.com/octo/ghbot/conventional"
+	"github.com/octo/ghbot/event"
+)
+
+const (
+	checkName  = "Conventional Commits"
+	detailsURL = "https://www.conventionalcommits.org/"
+)
+
+var (
+	// lintCommits enables checking commit messages in addition to the
+	// pull request title.
+	lintCommits = true
+	// maxHeaderLength is the maximum length of the first line of commit
+	// messages. Zero disables the limit.
+	maxHeaderLength = 100
+	// violationConclusion is the check's conclusion if violations are found.
+	violationConclusion = client.ConclusionFailure
+)
+
+func init() {
+	event.PullRequestHandler("commitlint", handler)
+}
+
+func handler(ctx context.Context, e *github.PullRequestEvent) error {
+	triggerOn := map[string]bool{
+		"edited":      true,
+		"opened":      true,
+		"reopened":    true,
+		"synchronize": true,
+	}
+	if !triggerOn[e.GetAction()] {
+		return nil
+	}
+
+	c, err := client.New(ctx, client.DefaultOwner, client.DefaultRepo)
+	if err != nil {
+		return err
+	}
+
+	return process(ctx, c, c.WrapPR(e.GetPullRequest()))
+}
+
+// violation is a problem with the pull request title or a commit message.
+type violation struct {
+	// subject is "Title" or the abbreviated commit SHA.
+	subject string
+	header  string
+	err     error
+}
+
+// lintMessage returns a problem with the commit message msg, or nil.
+func lintMessage(msg string) error {
+	if _, err := conventional.ParseMessage(msg); err != nil {
+		return err
+	}
+
+	header, _, _ := strings.Cut(msg, "\n")
+	if n := utf8.RuneCountInString(header); maxHeaderLength > 0 && n > maxHeaderLength {
+		return fmt.Errorf("header is %d characters long, use at most %d", n, maxHeaderLength)
+	}
+	return nil
+}
+
+func process(ctx context.Context, c *client.Client, pr *client.PR) error {
+	var violations []violation
+
+	if _, err := conventional.Parse(pr.GetTitle()); err != nil {
+		violations = append(violations, violation{subject: "Title", header: pr.GetTitle(), err: err})
+	}
+
+	if lintCommits {
+		commits, err := pr.Commits(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, rc := range commits {
+			// Merge commits are created by Github or "git merge".
+			if len(rc.Parents) > 1 {
+				continue
+			}
+
+			msg := rc.GetCommit().GetMessage()
+			if err := lintMessage(msg); err != nil {
+				header, _, _ := strings.Cut(msg, "\n")
+				violations = append(violations, violation{subject: rc.GetSHA()[:7], header: header, err: err})
+			}
+		}
+	}
+
+	check := client.Check{
+		Name:       checkName,
+		DetailsURL: detailsURL,
+		Conclusion: client.ConclusionSuccess,
+		Title:      "Title and commit messages follow the Conventional Commits format",
+		Summary:    fmt.Sprintf("Allowed types: %s", strings.Join(conventional.Types, ", ")),
+	}
+
+	if len(violations) != 0 {
+		var b strings.Builder
+		for _, v := range violations {
+			fmt.Fprintf(&b, "* **%s** %s: %v\n", v.subject, inlineCode(v.header), v.err)
+		}
+
+		check.Conclusion = violationConclusion
+		check.Title = fmt.Sprintf("%d violations of the Conventional Commits format", len(violations))
+		check.Summary = fmt.Sprintf("Use `<type>[(<scope>)][!]: <description>`, where type is one of %s.", strings.Join(conventional.Types, ", "))
+		check.Text = b.String()
+	}
+
+	return pr.SetCheck(ctx, check)
+}
+
+// inlineCode formats s as Markdown inline code. Backticks in s would end a
+// code span delimited by a single backtick, so the delimiter is made longer
+// than any run of backticks in s.
+func inlineCode(s string) string {
+	var longest, run int
+	for _, r := range s {
+		if r != '`' {
+			run = 0
+			continue
+		}
+		run++
+		if run > longest {
+			longest = run
+		}
+	}
+
+	if longest == 0 {
+		return "`" + s + "`"
+	}
+	delim := strings.Repeat("`", longest+1)
+	return delim + " " + s + " " + delim
+}
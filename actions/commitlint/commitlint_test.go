@@ -0,0 +1,47 @@
+package commitlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintMessage(t *testing.T) {
+	cases := []struct {
+		msg     string
+		wantErr bool
+	}{
+		{"fix(cpu): Handle overflows.", false},
+		{"feat: Add the foo plugin.\n\nChangeLog: Foo plugin: New plugin.", false},
+		{"feat!: Drop support for Python 2.", false},
+		{"[collectd 6] fix: Use the new API.", false},
+		{"Handle overflows.", true},
+		{"bugfix: Handle overflows.", true},
+		{"fix(): Handle overflows.", true},
+		{"fix: Handle overflows.\nThe body must be separated by an empty line.", true},
+		{"fix: " + strings.Repeat("x", maxHeaderLength-5), false},
+		{"fix: " + strings.Repeat("x", maxHeaderLength-4), true},
+	}
+
+	for _, tc := range cases {
+		err := lintMessage(tc.msg)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("lintMessage(%q) = %v, want error %v", tc.msg, err, tc.wantErr)
+		}
+	}
+}
+
+func TestInlineCode(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"fix: Handle overflows.", "`fix: Handle overflows.`"},
+		{"fix: Rename `foo`.", "`` fix: Rename `foo`. ``"},
+		{"docs: Explain ``` fences", "```` docs: Explain ``` fences ````"},
+	}
+
+	for _, tc := range cases {
+		if got := inlineCode(tc.in); got != tc.want {
+			t.Errorf("inlineCode(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
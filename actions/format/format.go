@@ -35,6 +35,7 @@ var (
 
 func init() {
 	event.PullRequestHandler("format", processPullRequestEvent)
+	event.MergeGroupHandler("format", client.MergeGroupHandler(process))
 }
 
 func hasAnySuffix(s string, suffixes []string) bool {
@@ -65,14 +66,18 @@ func processPullRequestEvent(ctx context.Context, e *github.PullRequestEvent) er
 	}
 
 	pr := c.WrapPR(e.PullRequest)
-	ref := pr.Head.GetSHA()
+	return process(ctx, c, pr, pr.Head.GetSHA())
+}
 
+// process checks the formatting of the files changed by pr and sets the
+// clang-format status on the commit ref.
+func process(ctx context.Context, c *client.Client, pr *client.PR, ref string) error {
 	files, err := pr.Files(ctx)
 	if err != nil {
 		return err
 	}
 
-	stage := c.NewStage(e.PullRequest)
+	stage := c.NewStage(pr.PullRequest)
 	ch := make(chan checkFileStatus)
 	wg := &sync.WaitGroup{}
 
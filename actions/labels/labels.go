@@ -12,12 +12,12 @@ package labels
 import (
 	"context"
 	"fmt"
-	"regexp"
-	"strings"
 
 	"bitbucket.org/creachadair/stringset"
 	"github.com/google/go-github/github"
+	"github.com/octo/ghbot/actions/labelsync"
 	"github.com/octo/ghbot/client"
+	"github.com/octo/ghbot/conventional"
 	"github.com/octo/ghbot/event"
 )
 
@@ -36,6 +36,8 @@ var requiredLabels = stringset.New(labelFix, labelFeature, labelMaintenance)
 
 func init() {
 	event.PullRequestHandler("labels", handler)
+	event.MergeGroupHandler("labels", client.MergeGroupHandler(process))
+	labelsync.Require("labels", requiredLabels.Elements()...)
 }
 
 func handler(ctx context.Context, e *github.PullRequestEvent) error {
@@ -50,18 +52,21 @@ func handler(ctx context.Context, e *github.PullRequestEvent) error {
 		return nil
 	}
 
-	var gotLabels stringset.Set
-	for _, label := range e.GetPullRequest().Labels {
-		gotLabels.Add(label.GetName())
-	}
-
 	c, err := client.New(ctx, client.DefaultOwner, client.DefaultRepo)
 	if err != nil {
 		return err
 	}
 
 	pr := c.WrapPR(e.GetPullRequest())
-	ref := pr.Head.GetSHA()
+	return process(ctx, c, pr, pr.Head.GetSHA())
+}
+
+// process sets the Labels status of pr on the commit ref.
+func process(ctx context.Context, c *client.Client, pr *client.PR, ref string) error {
+	var gotLabels stringset.Set
+	for _, label := range pr.Labels {
+		gotLabels.Add(label.GetName())
+	}
 
 	relevantLabels := gotLabels.Intersect(requiredLabels)
 	if relevantLabels.Len() == 1 {
@@ -96,25 +101,27 @@ func handler(ctx context.Context, e *github.PullRequestEvent) error {
 		detailsURL, ref)
 }
 
+// prefixToLabel maps conventional commit types to labels. Types missing from
+// this map don't imply a label.
+var prefixToLabel = map[string]string{
+	"feat":     labelFeature,
+	"fix":      labelFix,
+	"build":    labelMaintenance,
+	"chore":    labelMaintenance,
+	"ci":       labelMaintenance,
+	"docs":     labelFix,
+	"style":    labelMaintenance,
+	"refactor": labelMaintenance,
+	"perf":     labelFeature,
+	"test":     labelMaintenance,
+}
+
 func guessLabel(pr *client.PR) (string, bool) {
-	prefixToLabel := map[string]string{
-		"feat":     labelFeature,
-		"fix":      labelFix,
-		"build":    labelMaintenance,
-		"chore":    labelMaintenance,
-		"ci":       labelMaintenance,
-		"docs":     labelFix,
-		"style":    labelMaintenance,
-		"refactor": labelMaintenance,
-		"perf":     labelFeature,
-		"test":     labelMaintenance,
+	h, err := conventional.Parse(pr.GetTitle())
+	if err != nil {
+		return "", false
 	}
-	re := regexp.MustCompile(`^(feat|fix|build|chore|ci|docs|style|refactor|perf|test)\b`)
-
-	title := strings.TrimPrefix(pr.GetTitle(), "[collectd 6] ")
-
-	prefix := re.FindString(title)
 
-	label, ok := prefixToLabel[prefix]
+	label, ok := prefixToLabel[h.Type]
 	return label, ok
 }
@@ -1,10 +1,12 @@
 package labels
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/google/go-github/github"
 	"github.com/octo/ghbot/client"
+	"github.com/octo/ghbot/conventional"
 )
 
 func TestGuessLabel(t *testing.T) {
@@ -42,3 +44,32 @@ func TestGuessLabel(t *testing.T) {
 		}
 	}
 }
+
+func TestPathLabels(t *testing.T) {
+	cases := []struct {
+		files []string
+		want  []string
+	}{
+		{[]string{"src/write_http.c"}, []string{"Write plugin"}},
+		{[]string{"src/write_http.c", "src/collectd.conf.pod"}, []string{"Documentation", "Write plugin"}},
+		{[]string{".github/workflows/build.yml"}, []string{"CI"}},
+		{[]string{"src/utils/write_foo.c"}, nil},
+		{[]string{"src/cpu.c"}, nil},
+		{nil, nil},
+	}
+
+	for _, tc := range cases {
+		got := pathLabels(pathRules, tc.files)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("pathLabels(%q) = %q, want %q", tc.files, got, tc.want)
+		}
+	}
+}
+
+func TestPrefixToLabel(t *testing.T) {
+	for _, typ := range conventional.Types {
+		if _, ok := prefixToLabel[typ]; !ok {
+			t.Errorf("prefixToLabel[%q] is not set", typ)
+		}
+	}
+}
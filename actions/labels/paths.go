@@ -0,0 +1,147 @@
+package labels
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/go-github/github"
+	"github.com/mtraver/gaelog"
+	"github.com/octo/ghbot/actions/labelsync"
+	"github.com/octo/ghbot/client"
+	"github.com/octo/ghbot/event"
+	"github.com/octo/ghbot/glob"
+)
+
+// pathRule adds Label to pull requests changing a file matching Pattern.
+//
+// Patterns are matched against the full path using glob.Match.
+type pathRule struct {
+	Pattern string
+	Label   string
+}
+
+var pathRules = []pathRule{
+	{Pattern: "src/write_*.c", Label: "Write plugin"},
+	{Pattern: "src/*.pod", Label: "Documentation"},
+	{Pattern: "src/collectd*.conf.*", Label: "Documentation"},
+	{Pattern: ".github/**", Label: "CI"},
+	{Pattern: "build.sh", Label: "Build system"},
+	{Pattern: "configure.ac", Label: "Build system"},
+	{Pattern: "Makefile.am", Label: "Build system"},
+}
+
+func init() {
+	event.PullRequestHandler("pathlabels", pathHandler)
+	for _, r := range pathRules {
+		labelsync.Require("pathlabels", r.Label)
+	}
+}
+
+// pathLabels returns the labels rules assign to a pull request changing files.
+func pathLabels(rules []pathRule, files []string) []string {
+	set := map[string]bool{}
+	for _, r := range rules {
+		for _, f := range files {
+			if glob.Match(r.Pattern, f) {
+				set[r.Label] = true
+				break
+			}
+		}
+	}
+
+	var ret []string
+	for l := range set {
+		ret = append(ret, l)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func pathHandler(ctx context.Context, e *github.PullRequestEvent) error {
+	triggerOn := map[string]bool{
+		"opened":      true,
+		"reopened":    true,
+		"synchronize": true,
+	}
+	if !triggerOn[e.GetAction()] {
+		return nil
+	}
+
+	c, err := client.New(ctx, client.DefaultOwner, client.DefaultRepo)
+	if err != nil {
+		return err
+	}
+
+	return processPaths(ctx, c, c.WrapPR(e.GetPullRequest()))
+}
+
+// processPaths adds the labels pathRules assign to pr and removes labels that
+// no longer apply. Labels are only removed if they were added by the bot, so
+// that labels set by humans are left alone.
+func processPaths(ctx context.Context, c *client.Client, pr *client.PR) error {
+	prFiles, err := pr.Files(ctx)
+	if err != nil {
+		return err
+	}
+
+	var files []string
+	for _, f := range prFiles {
+		files = append(files, f.Filename)
+	}
+
+	want := map[string]bool{}
+	for _, l := range pathLabels(pathRules, files) {
+		want[l] = true
+	}
+
+	issue, err := pr.Issue(ctx)
+	if err != nil {
+		return err
+	}
+
+	managed := map[string]bool{}
+	for _, r := range pathRules {
+		managed[r.Label] = true
+	}
+
+	var remove []string
+	for _, l := range issue.Labels {
+		if managed[l.GetName()] && !want[l.GetName()] {
+			remove = append(remove, l.GetName())
+		}
+	}
+
+	if len(remove) != 0 {
+		self, err := c.Login(ctx)
+		if err != nil {
+			return err
+		}
+
+		labeledBy, err := issue.LabeledBy(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, l := range remove {
+			if labeledBy[l] != self {
+				continue
+			}
+			gaelog.Infof(ctx, "pathlabels: removing %q from %v", l, pr)
+			if err := issue.RemoveLabel(ctx, l); err != nil {
+				return err
+			}
+		}
+	}
+
+	for l := range want {
+		if issue.HasLabel(l) {
+			continue
+		}
+		gaelog.Infof(ctx, "pathlabels: adding %q to %v", l, pr)
+		if err := issue.AddLabel(ctx, l); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
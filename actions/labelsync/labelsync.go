@@ -0,0 +1,280 @@
+// Package labelsync keeps the repository's labels in sync with a declared set.
+//
+// Labels are created if missing and their color and description are updated
+// when they differ from the declaration. Labels listed as an alias of a
+// declared label are renamed, or, if the declared label already exists, their
+// issues are moved to the declared label and the alias is deleted.
+//
+// Other actions declare the labels they depend on with Require. Required
+// labels that don't exist after syncing are reported.
+package labelsync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/github"
+	"github.com/mtraver/gaelog"
+	"github.com/octo/ghbot/client"
+	"github.com/octo/ghbot/command"
+	"github.com/octo/ghbot/event"
+)
+
+// defaultBranch is the branch pushes to which trigger a sync.
+const defaultBranch = "main"
+
+// Label is the declaration of a repository label.
+type Label struct {
+	Name        string
+	Color       string
+	Description string
+	// Aliases are previous names of the label.
+	Aliases []string
+}
+
+var declared = []Label{
+	{Name: "Automerge", Color: "0e8a16", Description: "Merge automatically once all checks pass"},
+	{Name: "Feature", Color: "a2eeef", Description: "New feature or improvement"},
+	{Name: "Fix", Color: "d73a4a", Description: "Bug fix"},
+	{Name: "Maintenance", Color: "cfd3d7", Description: "Not included in the ChangeLog", Aliases: []string{"Unlisted Change"}},
+	{Name: "New plugin", Color: "5319e7", Description: "Adds a new plugin"},
+	{Name: "large-change-ok", Color: "fbca04", Description: "Large pull request approved by a maintainer"},
+	{Name: "size/XS", Color: "c2e0c6"},
+	{Name: "size/S", Color: "c2e0c6"},
+	{Name: "size/M", Color: "fef2c0"},
+	{Name: "size/L", Color: "f9d0c4"},
+	{Name: "size/XL", Color: "e99695"},
+	{Name: "Write plugin", Color: "bfd4f2", Description: "Changes a write plugin"},
+	{Name: "Documentation", Color: "0075ca", Description: "Changes documentation"},
+	{Name: "CI", Color: "ededed", Description: "Changes continuous integration"},
+	{Name: "Build system", Color: "ededed", Description: "Changes the build system"},
+	{Name: "Stale", Color: "ffffff", Description: "No activity for a long time"},
+}
+
+var (
+	mu       sync.Mutex
+	required = map[string][]string{}
+)
+
+// Require declares that action depends on the labels names.
+func Require(action string, names ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, n := range names {
+		required[n] = append(required[n], action)
+	}
+}
+
+func init() {
+	event.PushHandler("labelsync", processPush)
+	event.LabelHandler("labelsync", processLabel)
+	command.Register(command.Command{
+		Name:       "sync-labels",
+		Help:       "Syncs the repository's labels with the bot's configuration.",
+		Permission: command.PermissionAdmin,
+		Run:        processCommand,
+	})
+}
+
+func processPush(ctx context.Context, e *github.PushEvent) error {
+	if e.GetRef() != "refs/heads/"+defaultBranch {
+		return nil
+	}
+	return syncLabels(ctx)
+}
+
+func processLabel(ctx context.Context, e *github.LabelEvent) error {
+	return syncLabels(ctx)
+}
+
+func processCommand(ctx context.Context, req *command.Request) (string, error) {
+	r, err := run(ctx, req.Client)
+	if err != nil {
+		return "", err
+	}
+	return r.String(), nil
+}
+
+func syncLabels(ctx context.Context) error {
+	c, err := client.New(ctx, client.DefaultOwner, client.DefaultRepo)
+	if err != nil {
+		return err
+	}
+
+	r, err := run(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	for _, ch := range r.changes {
+		gaelog.Infof(ctx, "labelsync: %s", ch)
+	}
+	for _, m := range r.missing {
+		gaelog.Errorf(ctx, "labelsync: %s", m)
+	}
+	return nil
+}
+
+type changeKind int
+
+const (
+	create changeKind = iota
+	update
+	rename
+	migrate
+)
+
+// change is a modification of the repository's labels.
+type change struct {
+	kind  changeKind
+	label Label
+	// from is the alias being renamed or migrated.
+	from string
+}
+
+func (ch change) String() string {
+	switch ch.kind {
+	case create:
+		return fmt.Sprintf("created %q", ch.label.Name)
+	case update:
+		return fmt.Sprintf("updated color and description of %q", ch.label.Name)
+	case rename:
+		return fmt.Sprintf("renamed %q to %q", ch.from, ch.label.Name)
+	case migrate:
+		return fmt.Sprintf("moved issues from %q to %q and deleted %q", ch.from, ch.label.Name, ch.from)
+	}
+	return fmt.Sprintf("unknown change %d", ch.kind)
+}
+
+// plan returns the changes required to bring the existing labels in line with
+// the declared labels.
+func plan(declared []Label, existing []*github.Label) []change {
+	have := map[string]*github.Label{}
+	for _, l := range existing {
+		have[l.GetName()] = l
+	}
+
+	var ret []change
+	for _, d := range declared {
+		l, ok := have[d.Name]
+		if ok && (!strings.EqualFold(l.GetColor(), d.Color) || l.GetDescription() != d.Description) {
+			ret = append(ret, change{kind: update, label: d})
+		}
+
+		renamed := false
+		for _, a := range d.Aliases {
+			if _, aliasExists := have[a]; !aliasExists {
+				continue
+			}
+			if !ok && !renamed {
+				ret = append(ret, change{kind: rename, label: d, from: a})
+				renamed = true
+				continue
+			}
+			ret = append(ret, change{kind: migrate, label: d, from: a})
+		}
+
+		if !ok && !renamed {
+			ret = append(ret, change{kind: create, label: d})
+		}
+	}
+	return ret
+}
+
+func (ch change) apply(ctx context.Context, c *client.Client) error {
+	l := &github.Label{
+		Name:        github.String(ch.label.Name),
+		Color:       github.String(ch.label.Color),
+		Description: github.String(ch.label.Description),
+	}
+
+	switch ch.kind {
+	case create:
+		return c.CreateLabel(ctx, l)
+	case update:
+		return c.EditLabel(ctx, ch.label.Name, l)
+	case rename:
+		return c.EditLabel(ctx, ch.from, l)
+	case migrate:
+		issues, err := c.LabeledIssues(ctx, ch.from)
+		if err != nil {
+			return err
+		}
+		for _, i := range issues {
+			if err := i.AddLabel(ctx, ch.label.Name); err != nil {
+				return err
+			}
+		}
+		return c.DeleteLabel(ctx, ch.from)
+	}
+	return fmt.Errorf("unknown change %d", ch.kind)
+}
+
+// report summarizes a sync.
+type report struct {
+	changes []change
+	missing []string
+}
+
+func (r report) String() string {
+	if len(r.changes) == 0 && len(r.missing) == 0 {
+		return "All labels are up to date."
+	}
+
+	var b strings.Builder
+	for _, ch := range r.changes {
+		fmt.Fprintf(&b, "* %s\n", ch)
+	}
+	for _, m := range r.missing {
+		fmt.Fprintf(&b, "* :warning: %s\n", m)
+	}
+	return b.String()
+}
+
+func run(ctx context.Context, c *client.Client) (report, error) {
+	var r report
+
+	existing, err := c.Labels(ctx)
+	if err != nil {
+		return r, err
+	}
+
+	for _, ch := range plan(declared, existing) {
+		if err := ch.apply(ctx, c); err != nil {
+			return r, err
+		}
+		r.changes = append(r.changes, ch)
+	}
+
+	r.missing = missing(existing, declared)
+	return r, nil
+}
+
+// missing returns a description of each required label that neither exists
+// nor is declared.
+func missing(existing []*github.Label, declared []Label) []string {
+	have := map[string]bool{}
+	for _, l := range existing {
+		have[l.GetName()] = true
+	}
+	for _, d := range declared {
+		have[d.Name] = true
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	var ret []string
+	for name, actions := range required {
+		if have[name] {
+			continue
+		}
+		ret = append(ret, fmt.Sprintf("label %q used by %s does not exist", name, strings.Join(actions, ", ")))
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,56 @@
+package labelsync
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func label(name, color, desc string) *github.Label {
+	return &github.Label{Name: github.String(name), Color: github.String(color), Description: github.String(desc)}
+}
+
+func TestPlan(t *testing.T) {
+	fix := Label{Name: "Fix", Color: "d73a4a", Description: "Bug fix"}
+	maint := Label{Name: "Maintenance", Color: "cfd3d7", Aliases: []string{"Unlisted Change"}}
+
+	cases := []struct {
+		name     string
+		existing []*github.Label
+		want     []change
+	}{
+		{
+			name:     "up to date",
+			existing: []*github.Label{label("Fix", "D73A4A", "Bug fix"), label("Maintenance", "cfd3d7", "")},
+			want:     nil,
+		},
+		{
+			name:     "missing",
+			existing: nil,
+			want:     []change{{kind: create, label: fix}, {kind: create, label: maint}},
+		},
+		{
+			name:     "recolor",
+			existing: []*github.Label{label("Fix", "ffffff", "Bug fix"), label("Maintenance", "cfd3d7", "")},
+			want:     []change{{kind: update, label: fix}},
+		},
+		{
+			name:     "rename",
+			existing: []*github.Label{label("Fix", "d73a4a", "Bug fix"), label("Unlisted Change", "cfd3d7", "")},
+			want:     []change{{kind: rename, label: maint, from: "Unlisted Change"}},
+		},
+		{
+			name:     "migrate",
+			existing: []*github.Label{label("Fix", "d73a4a", "Bug fix"), label("Maintenance", "cfd3d7", ""), label("Unlisted Change", "cfd3d7", "")},
+			want:     []change{{kind: migrate, label: maint, from: "Unlisted Change"}},
+		},
+	}
+
+	for _, tc := range cases {
+		got := plan([]Label{fix, maint}, tc.existing)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: plan() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
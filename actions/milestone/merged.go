@@ -0,0 +1,160 @@
+package milestone
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+
+	"github.com/mtraver/gaelog"
+	"github.com/octo/ghbot/client"
+)
+
+// defaultBranch is the branch the next release is developed on.
+const defaultBranch = "main"
+
+// nextRelease is the milestone title of the next release from the default
+// branch. If empty, it is computed from the release branches and milestones,
+// see nextReleaseTitle.
+var nextRelease = ""
+
+var closingRE = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+#([1-9][0-9]*)\b`)
+
+// closingRefs returns the numbers of issues referenced by s with a closing
+// keyword, e.g. "Fixes #123".
+func closingRefs(s string) []int {
+	var (
+		ret  []int
+		seen = map[int]bool{}
+	)
+	for _, m := range closingRE.FindAllStringSubmatch(s, -1) {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || seen[n] {
+			continue
+		}
+		seen[n] = true
+		ret = append(ret, n)
+	}
+	return ret
+}
+
+// nextReleaseTitle returns the milestone of the next release from the default
+// branch: the open milestone with the smallest version greater than the
+// versions of all release branches.
+func nextReleaseTitle(ctx context.Context, c *client.Client, open []string) (string, bool, error) {
+	if nextRelease != "" {
+		return nextRelease, true, nil
+	}
+
+	branches, err := c.Branches(ctx)
+	if err != nil {
+		return "", false, err
+	}
+
+	var released []string
+	for _, b := range branches {
+		if t, ok := milestoneTitle(b); ok {
+			released = append(released, t)
+		}
+	}
+
+	title, ok := nextAfter(released, open)
+	return title, ok, nil
+}
+
+// nextAfter returns the milestone in open with the smallest version greater
+// than all versions in released. Patch releases of a released version, e.g.
+// "5.12.1" for "5.12", are made from the release branch and are skipped.
+func nextAfter(released, open []string) (string, bool) {
+	var latest []int
+	for _, r := range released {
+		if v, ok := parseVersion(r); ok && versionLess(latest, v) {
+			latest = v
+		}
+	}
+	if latest == nil {
+		return "", false
+	}
+
+	var (
+		next    string
+		nextVer []int
+	)
+	for _, t := range open {
+		v, ok := parseVersion(t)
+		if !ok {
+			continue
+		}
+		prefix := v
+		if len(prefix) > len(latest) {
+			prefix = prefix[:len(latest)]
+		}
+		if !versionLess(latest, prefix) {
+			continue
+		}
+		if nextVer == nil || versionLess(v, nextVer) {
+			next, nextVer = t, v
+		}
+	}
+
+	return next, nextVer != nil
+}
+
+// processMerged assigns a milestone to a merged pull request, if it doesn't
+// have one, and to the issues it fixes.
+func processMerged(ctx context.Context, c *client.Client, pr *client.PR) error {
+	i, err := pr.Issue(ctx)
+	if err != nil {
+		return err
+	}
+
+	milestones, err := c.Milestones(ctx)
+	if err != nil {
+		return err
+	}
+
+	id := i.Issue.Milestone.GetNumber()
+	if i.Issue.Milestone == nil {
+		title, ok := milestoneTitle(pr.GetBase().GetRef())
+		if !ok && pr.GetBase().GetRef() == defaultBranch {
+			var open []string
+			for t := range milestones {
+				open = append(open, t)
+			}
+
+			title, ok, err = nextReleaseTitle(ctx, c, open)
+			if err != nil {
+				return err
+			}
+		}
+		if !ok {
+			return nil
+		}
+
+		if id, ok = milestones[title]; !ok {
+			gaelog.Warningf(ctx, "milestone: no open milestone %q for %v", title, pr)
+			return nil
+		}
+
+		gaelog.Infof(ctx, "milestone: assigning merged %v to %q", pr, title)
+		if err := i.Milestone(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	for _, n := range closingRefs(pr.GetTitle() + "\n" + pr.GetBody()) {
+		fixed, err := c.Issue(ctx, n)
+		if err != nil {
+			return err
+		}
+		if fixed.IsPullRequest() || fixed.Issue.Milestone != nil {
+			continue
+		}
+
+		gaelog.Infof(ctx, "milestone: %v fixed by %v, assigning the same milestone", fixed, pr)
+		if err := fixed.Milestone(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,24 +1,70 @@
-// Package milestone sets the milestone of a PR based on the base branch.
+// Package milestone manages milestones based on branch names.
 //
-// Branches are expected to be named "collectd-<major>.<minor>", milestones are
-// expected to be titled "<major>.<minor>".
+// Release branches are mapped to milestones using branchPatterns, e.g. the
+// branch "collectd-5.12" to the milestone "5.12". The milestone is created
+// when the branch is created, and pull requests for the branch are assigned
+// to it, also when the base branch of a pull request is changed. When a
+// milestone is closed, its open issues and pull requests are moved to the
+// next milestone.
+//
+// Pull requests merged into the default branch are assigned to the next
+// release's milestone. Issues referenced with "Fixes #123" by a merged pull
+// request get the pull request's milestone.
 package milestone
 
 import (
 	"context"
+	"errors"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/google/go-github/github"
+	"github.com/mtraver/gaelog"
 	"github.com/octo/ghbot/client"
 	"github.com/octo/ghbot/event"
 )
 
+// branchPattern maps branches matching re to a milestone. The milestone title
+// may refer to capture groups of re, e.g. "$1".
+type branchPattern struct {
+	re        *regexp.Regexp
+	milestone string
+}
+
+var branchPatterns = []branchPattern{
+	{re: regexp.MustCompile(`^collectd-([0-9]+\.[0-9]+)$`), milestone: "$1"},
+}
+
 func init() {
 	event.PullRequestHandler("milestone", handler)
+	event.CreateHandler("milestone", processCreate)
+	event.MilestoneHandler("milestone", processMilestone)
+}
+
+// milestoneTitle returns the title of the milestone for branch. The boolean
+// return value is false if branch does not match any of branchPatterns.
+func milestoneTitle(branch string) (string, bool) {
+	for _, p := range branchPatterns {
+		m := p.re.FindStringSubmatchIndex(branch)
+		if m == nil {
+			continue
+		}
+		return string(p.re.ExpandString(nil, p.milestone, branch, m)), true
+	}
+	return "", false
 }
 
 func handler(ctx context.Context, e *github.PullRequestEvent) error {
-	if a := e.GetAction(); a != "opened" && a != "edited" {
+	switch e.GetAction() {
+	case "opened", "edited":
+	case "closed":
+		if !e.GetPullRequest().GetMerged() {
+			return nil
+		}
+	default:
 		return nil
 	}
 
@@ -28,14 +74,15 @@ func handler(ctx context.Context, e *github.PullRequestEvent) error {
 	}
 
 	pr := c.WrapPR(e.PullRequest)
-
-	ref := pr.PullRequest.Base.GetRef()
+	if e.GetAction() == "closed" {
+		return processMerged(ctx, c, pr)
+	}
 
 	// This is likely a PR for the main branch.
-	if !strings.HasPrefix(ref, "collectd-") {
+	title, ok := milestoneTitle(pr.PullRequest.Base.GetRef())
+	if !ok {
 		return nil
 	}
-	version := strings.TrimPrefix(ref, "collectd-")
 
 	// Only issues report the milestone :(
 	i, err := pr.Issue(ctx)
@@ -43,9 +90,18 @@ func handler(ctx context.Context, e *github.PullRequestEvent) error {
 		return err
 	}
 
-	// A milestone has already been set.
-	if i.Issue.Milestone != nil {
-		return nil
+	if cur := i.Issue.Milestone; cur != nil {
+		if cur.GetTitle() == title {
+			return nil
+		}
+
+		// A different milestone has been set. Only replace it if it
+		// belongs to another branch, i.e. the base branch has changed.
+		managed, err := branchMilestone(ctx, c, cur.GetTitle())
+		if err != nil || !managed {
+			return err
+		}
+		gaelog.Infof(ctx, "milestone: base branch of %v changed, moving it from %q to %q", pr, cur.GetTitle(), title)
 	}
 
 	milestones, err := c.Milestones(ctx)
@@ -53,9 +109,145 @@ func handler(ctx context.Context, e *github.PullRequestEvent) error {
 		return err
 	}
 
-	if id, ok := milestones[version]; ok {
+	if id, ok := milestones[title]; ok {
 		return i.Milestone(ctx, id)
 	}
 
 	return nil
 }
+
+// branchMilestone reports whether title is the milestone of an existing branch.
+func branchMilestone(ctx context.Context, c *client.Client, title string) (bool, error) {
+	branches, err := c.Branches(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, b := range branches {
+		if t, ok := milestoneTitle(b); ok && t == title {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// processCreate creates the milestone for new release branches.
+func processCreate(ctx context.Context, e *github.CreateEvent) error {
+	if e.GetRefType() != "branch" {
+		return nil
+	}
+
+	title, ok := milestoneTitle(e.GetRef())
+	if !ok {
+		return nil
+	}
+
+	c, err := client.New(ctx, client.DefaultOwner, client.DefaultRepo)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.MilestoneByTitle(ctx, title)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	gaelog.Infof(ctx, "milestone: creating milestone %q for branch %q", title, e.GetRef())
+	_, err = c.CreateMilestone(ctx, title)
+	return err
+}
+
+// processMilestone moves open issues and pull requests of a closed milestone
+// to the next milestone.
+func processMilestone(ctx context.Context, e *github.MilestoneEvent) error {
+	if e.GetAction() != "closed" {
+		return nil
+	}
+
+	c, err := client.New(ctx, client.DefaultOwner, client.DefaultRepo)
+	if err != nil {
+		return err
+	}
+
+	closed := e.GetMilestone()
+	issues, err := c.MilestoneIssues(ctx, closed.GetNumber(), "open")
+	if err != nil || len(issues) == 0 {
+		return err
+	}
+
+	milestones, err := c.Milestones(ctx)
+	if err != nil {
+		return err
+	}
+
+	var titles []string
+	for t := range milestones {
+		titles = append(titles, t)
+	}
+
+	next, ok := nextMilestone(closed.GetTitle(), titles)
+	if !ok {
+		gaelog.Warningf(ctx, "milestone: no milestone after %q, leaving %d open items", closed.GetTitle(), len(issues))
+		return nil
+	}
+
+	for _, i := range issues {
+		gaelog.Infof(ctx, "milestone: moving %v from %q to %q", i, closed.GetTitle(), next)
+		if err := i.Milestone(ctx, milestones[next]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseVersion parses milestone titles like "5.12" or "5.12.1". The boolean
+// return value is false if title is not a version number.
+func parseVersion(title string) ([]int, bool) {
+	var ret []int
+	for _, f := range strings.Split(title, ".") {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, false
+		}
+		ret = append(ret, n)
+	}
+	return ret, true
+}
+
+func versionLess(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// nextMilestone returns the milestone in titles with the smallest version
+// greater than closed. Titles that aren't version numbers are ignored.
+func nextMilestone(closed string, titles []string) (string, bool) {
+	cv, ok := parseVersion(closed)
+	if !ok {
+		return "", false
+	}
+
+	var candidates []string
+	for _, t := range titles {
+		if v, ok := parseVersion(t); ok && versionLess(cv, v) {
+			candidates = append(candidates, t)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		vi, _ := parseVersion(candidates[i])
+		vj, _ := parseVersion(candidates[j])
+		return versionLess(vi, vj)
+	})
+	return candidates[0], true
+}
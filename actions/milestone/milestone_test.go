@@ -0,0 +1,92 @@
+package milestone
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMilestoneTitle(t *testing.T) {
+	cases := []struct {
+		branch string
+		want   string
+		wantOK bool
+	}{
+		{"collectd-5.12", "5.12", true},
+		{"collectd-6.0", "6.0", true},
+		{"main", "", false},
+		{"collectd-5.12-fix", "", false},
+		{"backport/collectd-5.12/pr-1", "", false},
+	}
+
+	for _, tc := range cases {
+		got, ok := milestoneTitle(tc.branch)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("milestoneTitle(%q) = (%q, %v), want (%q, %v)", tc.branch, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestNextMilestone(t *testing.T) {
+	titles := []string{"Features", "5.12", "5.13", "5.12.1", "6.0"}
+
+	cases := []struct {
+		closed string
+		want   string
+		wantOK bool
+	}{
+		{"5.12", "5.12.1", true},
+		{"5.12.1", "5.13", true},
+		{"5.11", "5.12", true},
+		{"5.13", "6.0", true},
+		{"6.0", "", false},
+		{"Features", "", false},
+	}
+
+	for _, tc := range cases {
+		got, ok := nextMilestone(tc.closed, titles)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("nextMilestone(%q) = (%q, %v), want (%q, %v)", tc.closed, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestClosingRefs(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []int
+	}{
+		{"Fixes #123", []int{123}},
+		{"This closes #1 and resolves #2.\nFixed: #3", []int{1, 2, 3}},
+		{"fix #4, fix #4", []int{4}},
+		{"See #5", nil},
+		{"Prefixes #6", nil},
+	}
+
+	for _, tc := range cases {
+		if got := closingRefs(tc.in); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("closingRefs(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestNextAfter(t *testing.T) {
+	open := []string{"Features", "5.12.1", "5.13", "6.0"}
+
+	cases := []struct {
+		released []string
+		want     string
+		wantOK   bool
+	}{
+		{[]string{"5.11", "5.12"}, "5.13", true},
+		{[]string{"5.12.1"}, "5.13", true},
+		{[]string{"5.13"}, "6.0", true},
+		{nil, "", false},
+	}
+
+	for _, tc := range cases {
+		got, ok := nextAfter(tc.released, open)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("nextAfter(%q) = (%q, %v), want (%q, %v)", tc.released, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
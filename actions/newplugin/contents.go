@@ -0,0 +1,108 @@
+package newplugin
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// requirement is something a pull request adding a new plugin has to add to
+// one of the repository's files.
+type requirement struct {
+	file string
+	desc string
+	// pattern returns the regular expression the file has to match for the
+	// plugin name.
+	pattern func(name string) string
+}
+
+var requirements = []requirement{
+	{
+		file:    "src/collectd.conf.pod",
+		desc:    "a `=head2 Plugin C<%s>` section",
+		pattern: func(n string) string { return `(?m)^=head2 Plugin C<` + n + `>` },
+	},
+	{
+		file:    "src/collectd.conf.in",
+		desc:    "a `LoadPlugin %s` line",
+		pattern: func(n string) string { return `(?m)^[#@A-Z_]*LoadPlugin\s+"?` + n + `"?\s*$` },
+	},
+	{
+		file:    "src/collectd.conf.in",
+		desc:    "a `<Plugin %s>` block",
+		pattern: func(n string) string { return `(?m)^#?\s*<Plugin\s+"?` + n + `"?>` },
+	},
+	{
+		file:    "Makefile.am",
+		desc:    "a `BUILD_PLUGIN_%s` conditional",
+		pattern: func(n string) string { return `\bBUILD_PLUGIN_` + strings.ToUpper(n) + `\b` },
+	},
+	{
+		file:    "configure.ac",
+		desc:    "an `AC_PLUGIN([%s], …)` entry",
+		pattern: func(n string) string { return `AC_PLUGIN\(\[` + n + `\]` },
+	},
+	{
+		file:    "README",
+		desc:    "a `- %s` entry in the list of plugins",
+		pattern: func(n string) string { return `(?m)^\s*-\s+` + n + `\s*$` },
+	},
+	{
+		file:    "AUTHORS",
+		desc:    "a `%s plugin` entry",
+		pattern: func(n string) string { return `(?i)\b` + n + `\s+plugin` },
+	},
+}
+
+// requiredFiles are the files checked for the requirements.
+func requiredFiles() []string {
+	var (
+		ret  []string
+		seen = map[string]bool{}
+	)
+	for _, r := range requirements {
+		if !seen[r.file] {
+			seen[r.file] = true
+			ret = append(ret, r.file)
+		}
+	}
+	return ret
+}
+
+// result is the outcome of checking one requirement for one plugin.
+type result struct {
+	plugin string
+	desc   string
+	file   string
+	ok     bool
+}
+
+func (r result) String() string {
+	mark := "✓"
+	if !r.ok {
+		mark = "✗"
+	}
+	return fmt.Sprintf("%s `%s`: %s", mark, r.file, r.desc)
+}
+
+// checkContents checks the requirements for plugin name. contents holds the
+// content of the required files at the checked commit, keyed by path,
+// whether or not the pull request changed them. Files missing from
+// contents, i.e. files that don't exist or were removed, fail all their
+// requirements.
+func checkContents(name string, contents map[string]string) []result {
+	var ret []result
+	for _, r := range requirements {
+		content, ok := contents[r.file]
+		if ok {
+			ok = regexp.MustCompile(r.pattern(regexp.QuoteMeta(name))).MatchString(content)
+		}
+		ret = append(ret, result{
+			plugin: name,
+			desc:   fmt.Sprintf(r.desc, name),
+			file:   r.file,
+			ok:     ok,
+		})
+	}
+	return ret
+}
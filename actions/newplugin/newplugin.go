@@ -1,13 +1,25 @@
+// Package newplugin checks that pull requests adding a new plugin are
+// complete, e.g. that the plugin is documented.
+//
+// Pull requests are considered to add a new plugin if they have the "New
+// plugin" label. The label is set automatically if a pull request adds a
+// "src/<name>.c" file and a "BUILD_PLUGIN_<NAME>" build flag. The files at the
+// head of the pull request are checked against requirements, e.g. for a POD
+// section documenting the plugin, and the result is reported in a status.
 package newplugin
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"regexp"
 	"strings"
 	"sync"
 
 	"github.com/google/go-github/github"
+	"github.com/octo/ghbot/actions/labelsync"
 	"github.com/octo/ghbot/client"
 	"github.com/octo/ghbot/event"
 	"go.uber.org/multierr"
@@ -20,13 +32,19 @@ const (
 	detailsURL       = ""
 )
 
-var requiredFiles = []string{
-	"src/collectd.conf.pod",
-	"src/collectd.conf.in",
+// buildFiles are the files in which new plugins add a BUILD_PLUGIN_<NAME>
+// flag.
+var buildFiles = []string{
+	"configure.ac",
+	"Makefile.am",
 }
 
+var pluginSourceRE = regexp.MustCompile(`^src/([a-z0-9_]+)\.c$`)
+
 func init() {
 	event.PullRequestHandler("newplugin", processPullRequestEvent)
+	event.MergeGroupHandler("newplugin", client.MergeGroupHandler(process))
+	labelsync.Require("newplugin", newLabel)
 }
 
 func processPullRequestEvent(ctx context.Context, event *github.PullRequestEvent) error {
@@ -35,10 +53,13 @@ func processPullRequestEvent(ctx context.Context, event *github.PullRequestEvent
 		return err
 	}
 
-	return process(ctx, c, c.WrapPR(event.PullRequest))
+	pr := c.WrapPR(event.PullRequest)
+	return process(ctx, c, pr, pr.GetHead().GetSHA())
 }
 
-func process(ctx context.Context, c *client.Client, pr *client.PR) error {
+// process checks that a pull request adding a new plugin is complete and
+// sets the "New plugin" status on the commit ref.
+func process(ctx context.Context, c *client.Client, pr *client.PR, ref string) error {
 	if pr.GetMerged() || pr.GetState() != "open" {
 		return nil
 	}
@@ -49,7 +70,20 @@ func process(ctx context.Context, c *client.Client, pr *client.PR) error {
 	}
 
 	if !issue.HasLabel(newLabel) {
-		return nil
+		changes, err := pr.Changes(ctx)
+		if err != nil {
+			return err
+		}
+
+		plugins := newPlugins(changes)
+		if len(plugins) == 0 {
+			return nil
+		}
+
+		log.Printf("%v adds the plugins %q", pr, plugins)
+		if err := issue.AddLabel(ctx, newLabel); err != nil {
+			return err
+		}
 	}
 
 	wg := sync.WaitGroup{}
@@ -66,7 +100,7 @@ func process(ctx context.Context, c *client.Client, pr *client.PR) error {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if err := checkFiles(ctx, c, pr); err != nil {
+		if err := checkFiles(ctx, c, pr, ref); err != nil {
 			ch <- err
 		}
 	}()
@@ -103,33 +137,123 @@ func setMilestone(ctx context.Context, c *client.Client, issue *client.Issue) er
 	return issue.Milestone(ctx, id)
 }
 
-func checkFiles(ctx context.Context, c *client.Client, pr *client.PR) error {
-	files, err := pr.Files(ctx)
+// checkFiles verifies that each new plugin is documented and built, and
+// reports the result in a status on the commit ref.
+func checkFiles(ctx context.Context, c *client.Client, pr *client.PR, ref string) error {
+	changes, err := pr.Changes(ctx)
 	if err != nil {
 		return fmt.Errorf("newplugin: %v", err)
 	}
 
-	got := map[string]struct{}{}
-	for _, f := range files {
-		got[f.Filename] = struct{}{}
+	plugins := newPlugins(changes)
+	if len(plugins) == 0 {
+		// The label was set manually. Check all added plugin sources.
+		for _, f := range changes {
+			if m := pluginSourceRE.FindStringSubmatch(f.GetFilename()); m != nil && f.GetStatus() == "added" {
+				plugins = append(plugins, m[1])
+			}
+		}
 	}
 
-	var want []string
-	for _, f := range requiredFiles {
-		if _, ok := got[f]; ok {
+	required := map[string]bool{}
+	for _, f := range requiredFiles() {
+		required[f] = true
+	}
+
+	contents := map[string]string{}
+	for _, f := range changes {
+		if !required[f.GetFilename()] {
+			continue
+		}
+		delete(required, f.GetFilename())
+		if f.GetStatus() == "removed" {
 			continue
 		}
 
-		want = append(want, f)
+		content, err := pr.Blob(ctx, f.GetSHA())
+		if err != nil {
+			return fmt.Errorf("newplugin: Blob(%q): %v", f.GetFilename(), err)
+		}
+		contents[f.GetFilename()] = content
 	}
 
-	ref := pr.GetHead().GetSHA()
-	status := client.StatusSuccess
-	msg := "All required files touched"
-	if len(want) != 0 {
-		status = client.StatusFailure
-		msg = "Document new plugin in: " + strings.Join(want, ", ")
+	// Files not changed by the pull request may satisfy requirements, too,
+	// e.g. if the author is already listed in AUTHORS.
+	for f := range required {
+		content, err := c.FileContent(ctx, f, ref)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("newplugin: %v", err)
+		}
+		contents[f] = content
 	}
 
-	return c.CreateStatus(ctx, checkName, status, msg, detailsURL, ref)
+	check := client.Check{
+		Name:       checkName,
+		Ref:        ref,
+		DetailsURL: detailsURL,
+		Conclusion: client.ConclusionSuccess,
+		Title:      "The new plugin is documented and built",
+	}
+
+	if len(plugins) == 0 {
+		check.Conclusion = client.ConclusionFailure
+		check.Title = "No new plugin found"
+		check.Summary = fmt.Sprintf("The pull request has the %q label, but does not add a plugin source file (`src/<name>.c`).", newLabel)
+		return pr.SetCheck(ctx, check)
+	}
+
+	var (
+		b       strings.Builder
+		missing int
+	)
+	for _, name := range plugins {
+		fmt.Fprintf(&b, "### %s plugin\n\n", name)
+		for _, r := range checkContents(name, contents) {
+			if !r.ok {
+				missing++
+			}
+			fmt.Fprintf(&b, "* %v\n", r)
+		}
+		fmt.Fprintln(&b)
+	}
+
+	check.Summary = b.String()
+	if missing != 0 {
+		check.Conclusion = client.ConclusionFailure
+		check.Title = fmt.Sprintf("%d items missing for the new plugin", missing)
+	}
+
+	return pr.SetCheck(ctx, check)
+}
+
+// newPlugins returns the names of plugins added by changes. A plugin is
+// considered new if its source file "src/<name>.c" is added and one of
+// buildFiles gains a "BUILD_PLUGIN_<NAME>" flag.
+func newPlugins(changes []*github.CommitFile) []string {
+	var (
+		candidates []string
+		buildPatch string
+	)
+	for _, f := range changes {
+		if m := pluginSourceRE.FindStringSubmatch(f.GetFilename()); m != nil && f.GetStatus() == "added" {
+			candidates = append(candidates, m[1])
+		}
+		for _, b := range buildFiles {
+			if f.GetFilename() == b {
+				buildPatch += f.GetPatch() + "\n"
+			}
+		}
+	}
+
+	var ret []string
+	for _, name := range candidates {
+		re := regexp.MustCompile(`(?m)^\+.*\bBUILD_PLUGIN_` + strings.ToUpper(name) + `\b`)
+		if re.MatchString(buildPatch) {
+			ret = append(ret, name)
+		}
+	}
+	return ret
 }
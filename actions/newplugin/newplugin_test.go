@@ -0,0 +1,69 @@
+package newplugin
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func file(name, status, patch string) *github.CommitFile {
+	return &github.CommitFile{
+		Filename: github.String(name),
+		Status:   github.String(status),
+		Patch:    github.String(patch),
+	}
+}
+
+func TestNewPlugins(t *testing.T) {
+	configure := file("configure.ac", "modified", "@@ -1,1 +1,2 @@\n AC_PLUGIN([cpu], ...)\n+AC_PLUGIN([foo], [$with_foo], [Foo statistics])\n+AM_CONDITIONAL([BUILD_PLUGIN_FOO], [test \"x$enable_foo\" = \"xyes\"])")
+	makefile := file("Makefile.am", "modified", "@@ -1,1 +1,3 @@\n+if BUILD_PLUGIN_FOO\n+pkglib_LTLIBRARIES += foo.la\n+endif")
+
+	cases := []struct {
+		name  string
+		files []*github.CommitFile
+		want  []string
+	}{
+		{"new plugin", []*github.CommitFile{file("src/foo.c", "added", ""), configure, makefile}, []string{"foo"}},
+		{"Makefile.am only", []*github.CommitFile{file("src/foo.c", "added", ""), makefile}, []string{"foo"}},
+		{"modified source", []*github.CommitFile{file("src/foo.c", "modified", ""), configure}, nil},
+		{"no build flag", []*github.CommitFile{file("src/foo.c", "added", "")}, nil},
+		{"other flag", []*github.CommitFile{file("src/bar.c", "added", ""), configure}, nil},
+		{"removed flag", []*github.CommitFile{file("src/foo.c", "added", ""), file("Makefile.am", "modified", "-if BUILD_PLUGIN_FOO")}, nil},
+	}
+
+	for _, tc := range cases {
+		if got := newPlugins(tc.files); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: newPlugins() = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCheckContents(t *testing.T) {
+	contents := map[string]string{
+		"src/collectd.conf.pod": "=head2 Plugin C<cpu>\n\n=head2 Plugin C<foo>\n\nThe I<Foo plugin> collects …\n",
+		"src/collectd.conf.in":  "#@BUILD_PLUGIN_FOO_TRUE@LoadPlugin foo\n\n#<Plugin foo>\n#  Bar true\n#</Plugin>\n",
+		"Makefile.am":           "if BUILD_PLUGIN_FOO\npkglib_LTLIBRARIES += foo.la\nendif\n",
+		"configure.ac":          "AC_PLUGIN([foo], [$with_foo], [Foo statistics])\n",
+		"README":                "    - foo\n      Collects foo statistics.\n",
+	}
+
+	var missing []string
+	for _, r := range checkContents("foo", contents) {
+		if !r.ok {
+			missing = append(missing, r.file)
+		}
+	}
+
+	want := []string{"AUTHORS"}
+	if !reflect.DeepEqual(missing, want) {
+		t.Errorf("checkContents() missing %q, want %q", missing, want)
+	}
+
+	// A different plugin is not documented by these changes.
+	for _, r := range checkContents("bar", contents) {
+		if r.ok {
+			t.Errorf("checkContents(\"bar\"): %v, want failure", r)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Package reconcile catches up on webhooks the bot missed.
+//
+// If a webhook delivery fails or the bot is down, statuses like "ChangeLog"
+// are never set and automerge doesn't fire until the next push. Periodically,
+// this action lists all open pull requests and re-runs the actions whose status
+// or check run is missing on the head commit, as well as automerge for pull
+// requests with the Automerge label. Actions are run with a synthesized
+// "synchronize" event, i.e. by the same handlers as webhooks.
+//
+// Missing statuses are reconciled only once per head commit: if an action
+// doesn't publish its status when run, e.g. because it is disabled for the
+// repository, running it again every 30 minutes won't change that.
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/mtraver/gaelog"
+	"github.com/octo/ghbot/client"
+	"github.com/octo/ghbot/config"
+	"github.com/octo/ghbot/event"
+	"github.com/octo/ghbot/scheduler"
+	"go.uber.org/multierr"
+)
+
+// expected maps actions to the status or check run they publish on every pull
+// request.
+var expected = map[string]string{
+	"changelog":  "ChangeLog",
+	"commitlint": "Conventional Commits",
+	"format":     "clang-format",
+	"labels":     "Labels",
+	"size":       "Size",
+}
+
+// automergeLabel is the prefix of the labels requesting automerge, e.g.
+// "Automerge" and "Automerge: squash".
+const automergeLabel = "Automerge"
+
+const kind = "ReconciledHead"
+
+// head records the last head commit of a pull request that was reconciled.
+type head struct {
+	SHA  string
+	Time time.Time
+}
+
+// minAge is how long a pull request has to be left alone before it is
+// reconciled. Webhooks for more recent changes may still be in flight.
+var minAge = 15 * time.Minute
+
+func init() {
+	scheduler.Register("reconcile", "*/30 * * * *", process)
+}
+
+func process(ctx context.Context) error {
+	c, err := client.New(ctx, client.DefaultOwner, client.DefaultRepo)
+	if err != nil {
+		return err
+	}
+
+	prs, err := c.OpenPRs(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var errs error
+	for _, pr := range prs {
+		if now.Sub(pr.GetUpdatedAt()) < minAge {
+			continue
+		}
+
+		if err := reconcile(ctx, c, pr); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("%v: %w", pr, err))
+		}
+	}
+
+	return errs
+}
+
+func reconcile(ctx context.Context, c *client.Client, pr *client.PR) error {
+	published, err := contexts(ctx, c, pr)
+	if err != nil {
+		return err
+	}
+
+	var labels []string
+	for _, l := range pr.Labels {
+		labels = append(labels, l.GetName())
+	}
+
+	actions := missing(published, labels)
+	if len(actions) > 0 && actions[0] != "automerge" {
+		first, err := markReconciled(ctx, c, pr)
+		if err != nil {
+			return err
+		}
+		if !first {
+			actions = withoutChecks(actions)
+		}
+	}
+	if len(actions) == 0 {
+		return nil
+	}
+
+	gaelog.Infof(ctx, "reconcile: running %q for %v", actions, pr)
+	return event.RunPullRequestHandlers(ctx, event.SynchronizeEvent(pr.PullRequest), actions...)
+}
+
+// contexts returns the names of the statuses and check runs on the head commit
+// of pr.
+func contexts(ctx context.Context, c *client.Client, pr *client.PR) (map[string]bool, error) {
+	status, err := pr.CombinedStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("CombinedStatus(%v): %w", pr, err)
+	}
+
+	runs, err := c.CheckRuns(ctx, pr.GetHead().GetSHA())
+	if err != nil {
+		return nil, err
+	}
+
+	ret := map[string]bool{}
+	for _, s := range status.Statuses {
+		ret[s.GetContext()] = true
+	}
+	for _, r := range runs {
+		ret[r.GetName()] = true
+	}
+	return ret, nil
+}
+
+// missing returns the actions to run, given the statuses and check runs
+// already published and the labels of a pull request. Automerge goes last, so
+// it sees the statuses set by the other actions.
+func missing(published map[string]bool, labels []string) []string {
+	var ret []string
+	for action, name := range expected {
+		if !published[name] {
+			ret = append(ret, action)
+		}
+	}
+	sort.Strings(ret)
+
+	for _, l := range labels {
+		if strings.HasPrefix(l, automergeLabel) {
+			ret = append(ret, "automerge")
+			break
+		}
+	}
+
+	return ret
+}
+
+// withoutChecks removes the actions publishing a status from actions.
+func withoutChecks(actions []string) []string {
+	var ret []string
+	for _, a := range actions {
+		if _, ok := expected[a]; !ok {
+			ret = append(ret, a)
+		}
+	}
+	return ret
+}
+
+// markReconciled records that the head commit of pr has been reconciled. It
+// returns false if that head commit was reconciled before.
+func markReconciled(ctx context.Context, c *client.Client, pr *client.PR) (bool, error) {
+	db, err := config.Datastore(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	sha := pr.GetHead().GetSHA()
+	k := datastore.NameKey(kind, fmt.Sprintf("%s/%s#%d", c.Owner(), c.Repo(), pr.Number()), nil)
+
+	first := false
+	_, err = db.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		first = false
+
+		var h head
+		if err := tx.Get(k, &h); err != nil && !errors.Is(err, datastore.ErrNoSuchEntity) {
+			return err
+		}
+		if h.SHA == sha {
+			return nil
+		}
+
+		h = head{
+			SHA:  sha,
+			Time: time.Now(),
+		}
+		if _, err := tx.Put(k, &h); err != nil {
+			return err
+		}
+
+		first = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("reconcile: head of %v: %w", pr, err)
+	}
+
+	return first, nil
+}
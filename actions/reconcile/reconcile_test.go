@@ -0,0 +1,53 @@
+package reconcile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMissing(t *testing.T) {
+	all := map[string]bool{
+		"ChangeLog":            true,
+		"Conventional Commits": true,
+		"clang-format":         true,
+		"Labels":               true,
+		"Size":                 true,
+	}
+
+	cases := []struct {
+		published map[string]bool
+		labels    []string
+		want      []string
+	}{
+		{all, nil, nil},
+		{all, []string{"Fix"}, nil},
+		{all, []string{"Fix", "Automerge: squash"}, []string{"automerge"}},
+		{map[string]bool{"ChangeLog": true, "Size": true}, nil, []string{"commitlint", "format", "labels"}},
+		{nil, []string{"Automerge"}, []string{"changelog", "commitlint", "format", "labels", "size", "automerge"}},
+	}
+
+	for _, tc := range cases {
+		got := missing(tc.published, tc.labels)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("missing(%v, %q) = %q, want %q", tc.published, tc.labels, got, tc.want)
+		}
+	}
+}
+
+func TestWithoutChecks(t *testing.T) {
+	cases := []struct {
+		actions []string
+		want    []string
+	}{
+		{nil, nil},
+		{[]string{"commitlint", "format"}, nil},
+		{[]string{"changelog", "automerge"}, []string{"automerge"}},
+		{[]string{"automerge"}, []string{"automerge"}},
+	}
+
+	for _, tc := range cases {
+		if got := withoutChecks(tc.actions); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("withoutChecks(%q) = %q, want %q", tc.actions, got, tc.want)
+		}
+	}
+}
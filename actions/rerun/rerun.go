@@ -0,0 +1,71 @@
+// Package rerun re-runs the bot's pull request checks on demand.
+//
+// Statuses sometimes get lost, e.g. when format.collectd.org times out. Instead
+// of pushing a dummy commit, maintainers can comment "/ghbot rerun" on the pull
+// request, optionally followed by the names of the actions to run.
+//
+// The "Re-run" buttons of check runs are not supported: Github only sends
+// "rerequested" and "requested_action" events to the Github App owning the
+// check run, and the bot publishes commit statuses instead.
+package rerun
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mtraver/gaelog"
+	"github.com/octo/ghbot/client"
+	"github.com/octo/ghbot/command"
+	"github.com/octo/ghbot/event"
+)
+
+// defaultActions are the actions re-run if none are specified.
+var defaultActions = []string{
+	"changelog",
+	"commitlint",
+	"format",
+	"labels",
+	"newplugin",
+}
+
+func init() {
+	command.Register(command.Command{
+		Name:            "rerun",
+		Usage:           "[action...]",
+		Help:            fmt.Sprintf("Re-runs the bot's checks. Defaults to %s.", strings.Join(defaultActions, ", ")),
+		Permission:      command.PermissionWrite,
+		PullRequestOnly: true,
+		Run:             processCommand,
+	})
+}
+
+func processCommand(ctx context.Context, req *command.Request) (string, error) {
+	actions := defaultActions
+	if len(req.Args) != 0 {
+		actions = req.Args
+	}
+
+	known := map[string]bool{}
+	for _, name := range event.PullRequestHandlerNames() {
+		known[name] = true
+	}
+	for _, a := range actions {
+		if !known[a] {
+			return "", fmt.Errorf("unknown action %q, want one of %s", a, strings.Join(event.PullRequestHandlerNames(), ", "))
+		}
+	}
+
+	if err := rerun(ctx, req.PR, actions); err != nil {
+		return "", err
+	}
+
+	return "", nil
+}
+
+// rerun runs actions as if the head branch of pr had just been pushed to.
+func rerun(ctx context.Context, pr *client.PR, actions []string) error {
+	gaelog.Infof(ctx, "rerun: running %q for %v", actions, pr)
+
+	return event.RunPullRequestHandlers(ctx, event.SynchronizeEvent(pr.PullRequest), actions...)
+}
@@ -0,0 +1,175 @@
+// Package size labels pull requests by the number of changed lines.
+//
+// Each pull request gets exactly one of the "size/XS" … "size/XL" labels,
+// which is updated whenever the pull request changes. Generated and vendored
+// files are not counted. Optionally, a check fails (or stays pending) for pull
+// requests exceeding largeThreshold, unless a maintainer set the
+// "large-change-ok" label.
+package size
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/mtraver/gaelog"
+	"github.com/octo/ghbot/actions/labelsync"
+	"github.com/octo/ghbot/client"
+	"github.com/octo/ghbot/event"
+	"github.com/octo/ghbot/glob"
+)
+
+const (
+	checkName    = "Size"
+	labelPrefix  = "size/"
+	labelLargeOK = "large-change-ok"
+)
+
+// sizes maps the maximum number of changed lines to a label. The last entry
+// applies to all larger pull requests.
+var sizes = []struct {
+	max   int
+	label string
+}{
+	{9, labelPrefix + "XS"},
+	{29, labelPrefix + "S"},
+	{99, labelPrefix + "M"},
+	{499, labelPrefix + "L"},
+	{-1, labelPrefix + "XL"},
+}
+
+// excluded are glob patterns of generated and vendored files that are not
+// counted.
+var excluded = []string{
+	"**/*.pb-c.[ch]",
+	"**/*.pb.go",
+	"**/*_pb2.py",
+	"vendor/**",
+	"**/vendor/**",
+}
+
+var (
+	// largeThreshold is the number of changed lines above which a pull
+	// request is considered large.
+	largeThreshold = 1000
+	// largeConclusion is the conclusion of the check for large pull
+	// requests. Set to "" to disable the check.
+	largeConclusion = client.ConclusionNeutral
+)
+
+func init() {
+	event.PullRequestHandler("size", handler)
+	labelsync.Require("size", labelLargeOK)
+	for _, s := range sizes {
+		labelsync.Require("size", s.label)
+	}
+}
+
+// stats are the size of a pull request.
+type stats struct {
+	additions, deletions, files int
+}
+
+func (s stats) lines() int {
+	return s.additions + s.deletions
+}
+
+func (s stats) String() string {
+	return fmt.Sprintf("+%d −%d in %d files", s.additions, s.deletions, s.files)
+}
+
+func count(files []*github.CommitFile) stats {
+	var s stats
+	for _, f := range files {
+		if glob.MatchAny(excluded, f.GetFilename()) {
+			continue
+		}
+		s.additions += f.GetAdditions()
+		s.deletions += f.GetDeletions()
+		s.files++
+	}
+	return s
+}
+
+// sizeLabel returns the size label for a pull request changing lines lines.
+func sizeLabel(lines int) string {
+	for _, s := range sizes {
+		if s.max < 0 || lines <= s.max {
+			return s.label
+		}
+	}
+	return sizes[len(sizes)-1].label
+}
+
+func handler(ctx context.Context, e *github.PullRequestEvent) error {
+	switch e.GetAction() {
+	case "opened", "reopened", "synchronize":
+	case "labeled", "unlabeled":
+		if e.GetLabel().GetName() != labelLargeOK {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	c, err := client.New(ctx, client.DefaultOwner, client.DefaultRepo)
+	if err != nil {
+		return err
+	}
+
+	return process(ctx, c, c.WrapPR(e.GetPullRequest()))
+}
+
+func process(ctx context.Context, c *client.Client, pr *client.PR) error {
+	files, err := pr.Changes(ctx)
+	if err != nil {
+		return err
+	}
+	s := count(files)
+
+	issue, err := pr.Issue(ctx)
+	if err != nil {
+		return err
+	}
+
+	want := sizeLabel(s.lines())
+	for _, l := range issue.Labels {
+		name := l.GetName()
+		if !strings.HasPrefix(name, labelPrefix) || name == want {
+			continue
+		}
+		if err := issue.RemoveLabel(ctx, name); err != nil {
+			return err
+		}
+	}
+	if !issue.HasLabel(want) {
+		gaelog.Infof(ctx, "size: %v is %s (%v)", pr, want, s)
+		if err := issue.AddLabel(ctx, want); err != nil {
+			return err
+		}
+	}
+
+	if largeConclusion == "" {
+		return nil
+	}
+
+	check := client.Check{
+		Name:       checkName,
+		Conclusion: client.ConclusionSuccess,
+		Title:      fmt.Sprintf("%d lines changed", s.lines()),
+		Summary:    fmt.Sprintf("This pull request changes %v, not counting generated and vendored files.", s),
+	}
+	switch {
+	case s.lines() <= largeThreshold:
+	case issue.HasLabel(labelLargeOK):
+		check.Summary += fmt.Sprintf("\n\nThis is more than %d lines, but a maintainer set the %q label.", largeThreshold, labelLargeOK)
+	default:
+		check.Conclusion = largeConclusion
+		check.Title = fmt.Sprintf("Large change: %d lines changed", s.lines())
+		check.Summary += fmt.Sprintf("\n\nLarge changes are hard to review. Please consider splitting this pull request into smaller ones. "+
+			"If that is not possible, a maintainer can set the %q label.", labelLargeOK)
+	}
+
+	return pr.SetCheck(ctx, check)
+}
@@ -0,0 +1,42 @@
+package size
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestSizeLabel(t *testing.T) {
+	cases := []struct {
+		lines int
+		want  string
+	}{
+		{0, "size/XS"},
+		{9, "size/XS"},
+		{10, "size/S"},
+		{99, "size/M"},
+		{100, "size/L"},
+		{500, "size/XL"},
+		{100000, "size/XL"},
+	}
+
+	for _, tc := range cases {
+		if got := sizeLabel(tc.lines); got != tc.want {
+			t.Errorf("sizeLabel(%d) = %q, want %q", tc.lines, got, tc.want)
+		}
+	}
+}
+
+func TestCount(t *testing.T) {
+	files := []*github.CommitFile{
+		{Filename: github.String("src/cpu.c"), Additions: github.Int(10), Deletions: github.Int(5)},
+		{Filename: github.String("src/cpu.pod"), Additions: github.Int(3), Deletions: github.Int(0)},
+		{Filename: github.String("src/daemon/types.pb-c.c"), Additions: github.Int(5000), Deletions: github.Int(0)},
+		{Filename: github.String("vendor/lib/lib.go"), Additions: github.Int(100), Deletions: github.Int(100)},
+	}
+
+	want := stats{additions: 13, deletions: 5, files: 2}
+	if got := count(files); got != want {
+		t.Errorf("count() = %v, want %v", got, want)
+	}
+}
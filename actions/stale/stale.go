@@ -0,0 +1,345 @@
+// Package stale marks inactive issues and pull requests as stale and closes
+// them if they remain inactive.
+//
+// Items without activity for Config.DaysUntilStale days get the "Stale" label
+// and a comment. If there is no further activity for Config.DaysUntilClose
+// days, they are closed. Any activity by someone other than the bot removes
+// the label again. Items can be exempted by label, milestone or assignee.
+//
+// Repositories enable stale handling with a configPath file. The sweep runs
+// daily via the scheduler, unless the file sets "dry_run". Maintainers can
+// also run it with "/ghbot stale [dry-run]"; "dry-run" only lists what would
+// be done.
+package stale
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/mtraver/gaelog"
+	"github.com/octo/ghbot/actions/labelsync"
+	"github.com/octo/ghbot/client"
+	"github.com/octo/ghbot/command"
+	"github.com/octo/ghbot/event"
+	"github.com/octo/ghbot/scheduler"
+	"go.uber.org/multierr"
+)
+
+const labelStale = "Stale"
+
+// configPath is the file on the default branch configuring stale handling.
+// Repositories without it are not swept.
+const configPath = ".github/stale.json"
+
+// Config configures stale handling for a repository.
+type Config struct {
+	DaysUntilStale int `json:"days_until_stale"`
+	DaysUntilClose int `json:"days_until_close"`
+
+	ExemptLabels     []string `json:"exempt_labels"`
+	ExemptMilestones []string `json:"exempt_milestones"`
+	// ExemptAssigned exempts items with an assignee.
+	ExemptAssigned bool `json:"exempt_assigned"`
+
+	// DryRun makes the scheduled sweep only log what would be done.
+	DryRun bool `json:"dry_run"`
+
+	StaleComment string `json:"stale_comment"`
+	CloseComment string `json:"close_comment"`
+}
+
+// defaultConfig holds the defaults for settings missing from configPath.
+var defaultConfig = Config{
+	DaysUntilStale:   180,
+	DaysUntilClose:   30,
+	ExemptLabels:     []string{"Automerge", "Blocker", "Pinned", "Security"},
+	ExemptMilestones: []string{"Features"},
+	ExemptAssigned:   true,
+	StaleComment: "This has been inactive for a long time and is now marked as stale. " +
+		"It will be closed if there is no further activity. Comment or push to keep it open.",
+	CloseComment: "Closing due to inactivity. Feel free to reopen if this is still relevant.",
+}
+
+// parseConfig parses a JSON encoded Config. Missing settings are taken from
+// defaultConfig.
+func parseConfig(data []byte) (Config, error) {
+	cfg := defaultConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", configPath, err)
+	}
+	if cfg.DaysUntilStale <= 0 || cfg.DaysUntilClose <= 0 {
+		return Config{}, fmt.Errorf("%s: days_until_stale and days_until_close must be positive", configPath)
+	}
+	return cfg, nil
+}
+
+// loadConfig returns the stale configuration of the repository. If the
+// repository doesn't have one, os.ErrNotExist is returned.
+func loadConfig(ctx context.Context, c *client.Client) (Config, error) {
+	content, err := c.FileContent(ctx, configPath, "")
+	if err != nil {
+		return Config{}, err
+	}
+	return parseConfig([]byte(content))
+}
+
+func init() {
+	event.IssueCommentHandler("stale", processIssueComment)
+	event.IssuesHandler("stale", processIssues)
+	event.PullRequestHandler("stale", processPullRequest)
+	command.Register(command.Command{
+		Name:       "stale",
+		Usage:      "[dry-run]",
+		Help:       "Marks inactive issues and pull requests as stale and closes stale ones.",
+		Permission: command.PermissionAdmin,
+		Run:        processCommand,
+	})
+	labelsync.Require("stale", labelStale)
+	scheduler.Register("stale", "0 3 * * *", processSchedule)
+}
+
+func processIssueComment(ctx context.Context, e *github.IssueCommentEvent) error {
+	if e.GetAction() != "created" || !isStale(e.GetIssue()) {
+		return nil
+	}
+
+	c, err := client.New(ctx, client.DefaultOwner, client.DefaultRepo)
+	if err != nil {
+		return err
+	}
+	return unstale(ctx, c, c.WrapIssue(e.GetIssue()), e.GetSender().GetLogin())
+}
+
+func processIssues(ctx context.Context, e *github.IssuesEvent) error {
+	if a := e.GetAction(); (a != "edited" && a != "reopened") || !isStale(e.GetIssue()) {
+		return nil
+	}
+
+	c, err := client.New(ctx, client.DefaultOwner, client.DefaultRepo)
+	if err != nil {
+		return err
+	}
+	return unstale(ctx, c, c.WrapIssue(e.GetIssue()), e.GetSender().GetLogin())
+}
+
+func processPullRequest(ctx context.Context, e *github.PullRequestEvent) error {
+	if a := e.GetAction(); a != "edited" && a != "reopened" && a != "synchronize" {
+		return nil
+	}
+
+	// Pull request events don't include labels in the issue format.
+	for _, l := range e.GetPullRequest().Labels {
+		if l.GetName() != labelStale {
+			continue
+		}
+
+		c, err := client.New(ctx, client.DefaultOwner, client.DefaultRepo)
+		if err != nil {
+			return err
+		}
+
+		issue, err := c.WrapPR(e.GetPullRequest()).Issue(ctx)
+		if err != nil {
+			return err
+		}
+		return unstale(ctx, c, issue, e.GetSender().GetLogin())
+	}
+	return nil
+}
+
+func isStale(issue *github.Issue) bool {
+	for _, l := range issue.Labels {
+		if l.GetName() == labelStale {
+			return true
+		}
+	}
+	return false
+}
+
+// unstale removes the "Stale" label from issue, unless the activity was caused
+// by the bot itself.
+func unstale(ctx context.Context, c *client.Client, i *client.Issue, actor string) error {
+	self, err := c.Login(ctx)
+	if err != nil {
+		return err
+	}
+	if actor == self {
+		return nil
+	}
+
+	gaelog.Infof(ctx, "stale: activity by %s on %v, removing %q", actor, i, labelStale)
+	return i.RemoveLabel(ctx, labelStale)
+}
+
+func processSchedule(ctx context.Context) error {
+	c, err := client.New(ctx, client.DefaultOwner, client.DefaultRepo)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(ctx, c)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = sweep(ctx, c, cfg, time.Now())
+	return err
+}
+
+func processCommand(ctx context.Context, req *command.Request) (string, error) {
+	cfg, err := loadConfig(ctx, req.Client)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("stale handling is not configured, see %s", configPath)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// The command overrides the configured dry-run setting.
+	switch {
+	case len(req.Args) == 0:
+		cfg.DryRun = false
+	case len(req.Args) == 1 && req.Args[0] == "dry-run":
+		cfg.DryRun = true
+	default:
+		return "", fmt.Errorf("usage: stale [dry-run]")
+	}
+
+	actions, err := sweep(ctx, req.Client, cfg, time.Now())
+	if len(actions) == 0 && err == nil {
+		return "Nothing to do.", nil
+	}
+
+	var b strings.Builder
+	if cfg.DryRun {
+		fmt.Fprintln(&b, "Dry run, nothing has been changed:")
+		fmt.Fprintln(&b)
+	}
+	for _, a := range actions {
+		fmt.Fprintf(&b, "* %s\n", a)
+	}
+	if err != nil {
+		fmt.Fprintf(&b, "\nSome items failed: %v\n", err)
+	}
+	return b.String(), nil
+}
+
+// verdict is what happens to an item.
+type verdict int
+
+const (
+	keep verdict = iota
+	markStale
+	closeStale
+)
+
+// action is a change made, or proposed, by a sweep.
+type action struct {
+	issue   *client.Issue
+	verdict verdict
+}
+
+func (a action) String() string {
+	if a.verdict == closeStale {
+		return fmt.Sprintf("close %v", a.issue)
+	}
+	return fmt.Sprintf("mark %v as stale", a.issue)
+}
+
+// exempt reports whether issue is exempt from stale handling.
+func (cfg Config) exempt(issue *github.Issue) bool {
+	for _, l := range issue.Labels {
+		for _, e := range cfg.ExemptLabels {
+			if l.GetName() == e {
+				return true
+			}
+		}
+	}
+	for _, m := range cfg.ExemptMilestones {
+		if issue.GetMilestone().GetTitle() == m {
+			return true
+		}
+	}
+	return cfg.ExemptAssigned && len(issue.Assignees) != 0
+}
+
+// judge decides what happens to issue at time now.
+func (cfg Config) judge(issue *github.Issue, now time.Time) verdict {
+	if cfg.exempt(issue) {
+		return keep
+	}
+
+	stale := isStale(issue)
+
+	// Labeling and commenting update the issue, so for stale items this is
+	// the time since they were marked.
+	inactive := now.Sub(issue.GetUpdatedAt())
+	day := 24 * time.Hour
+
+	switch {
+	case stale && inactive >= time.Duration(cfg.DaysUntilClose)*day:
+		return closeStale
+	case !stale && inactive >= time.Duration(cfg.DaysUntilStale)*day:
+		return markStale
+	default:
+		return keep
+	}
+}
+
+// sweep marks and closes stale items and returns the actions taken. In dry-run
+// mode, the actions are only returned. Failing items don't stop the sweep; all
+// errors are returned together.
+func sweep(ctx context.Context, c *client.Client, cfg Config, now time.Time) ([]action, error) {
+	issues, err := c.OpenIssues(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ret  []action
+		errs error
+	)
+	for _, i := range issues {
+		v := cfg.judge(i.Issue, now)
+		if v == keep {
+			continue
+		}
+
+		a := action{issue: i, verdict: v}
+		gaelog.Infof(ctx, "stale: %s (dry run: %v)", a, cfg.DryRun)
+		if !cfg.DryRun {
+			if err := a.apply(ctx, cfg); err != nil {
+				errs = multierr.Append(errs, fmt.Errorf("%s: %w", a, err))
+				continue
+			}
+		}
+		ret = append(ret, a)
+	}
+
+	return ret, errs
+}
+
+func (a action) apply(ctx context.Context, cfg Config) error {
+	switch a.verdict {
+	case markStale:
+		if err := a.issue.AddLabel(ctx, labelStale); err != nil {
+			return err
+		}
+		return a.issue.Comment(ctx, cfg.StaleComment)
+	case closeStale:
+		if err := a.issue.Comment(ctx, cfg.CloseComment); err != nil {
+			return err
+		}
+		return a.issue.Close(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package stale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func TestJudge(t *testing.T) {
+	cfg := Config{
+		DaysUntilStale:   60,
+		DaysUntilClose:   7,
+		ExemptLabels:     []string{"Pinned"},
+		ExemptMilestones: []string{"Features"},
+		ExemptAssigned:   true,
+	}
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(n int) *time.Time {
+		t := now.Add(-time.Duration(n) * 24 * time.Hour)
+		return &t
+	}
+	labels := func(names ...string) []github.Label {
+		var ret []github.Label
+		for _, n := range names {
+			ret = append(ret, github.Label{Name: github.String(n)})
+		}
+		return ret
+	}
+
+	cases := []struct {
+		name  string
+		issue *github.Issue
+		want  verdict
+	}{
+		{"active", &github.Issue{UpdatedAt: daysAgo(10)}, keep},
+		{"inactive", &github.Issue{UpdatedAt: daysAgo(60)}, markStale},
+		{"stale, recent", &github.Issue{UpdatedAt: daysAgo(3), Labels: labels(labelStale)}, keep},
+		{"stale, expired", &github.Issue{UpdatedAt: daysAgo(7), Labels: labels(labelStale)}, closeStale},
+		{"exempt label", &github.Issue{UpdatedAt: daysAgo(100), Labels: labels("Pinned")}, keep},
+		{"exempt milestone", &github.Issue{UpdatedAt: daysAgo(100), Milestone: &github.Milestone{Title: github.String("Features")}}, keep},
+		{"assigned", &github.Issue{UpdatedAt: daysAgo(100), Assignees: []*github.User{{Login: github.String("octo")}}}, keep},
+	}
+
+	for _, tc := range cases {
+		if got := cfg.judge(tc.issue, now); got != tc.want {
+			t.Errorf("%s: judge() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := parseConfig([]byte(`{"days_until_stale": 90, "dry_run": true, "exempt_labels": ["Pinned"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DaysUntilStale != 90 || !cfg.DryRun || len(cfg.ExemptLabels) != 1 {
+		t.Errorf("parseConfig() = %+v, want the settings from the file", cfg)
+	}
+	if cfg.DaysUntilClose != defaultConfig.DaysUntilClose || cfg.StaleComment != defaultConfig.StaleComment {
+		t.Errorf("parseConfig() = %+v, want defaults for missing settings", cfg)
+	}
+
+	for _, data := range []string{`{"days_until_close": 0}`, `{"days_until_stale": "soon"}`, `[]`} {
+		if _, err := parseConfig([]byte(data)); err == nil {
+			t.Errorf("parseConfig(%s) = nil, want error", data)
+		}
+	}
+}
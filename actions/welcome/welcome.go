@@ -0,0 +1,149 @@
+// Package welcome greets first-time contributors.
+//
+// When a first-time contributor opens a pull request or an issue, the bot
+// posts a comment explaining what it expects, with links to the relevant
+// sections of the contributing guidelines. The comment is a sticky comment, so
+// it is updated rather than posted twice.
+//
+// Repositories can override the messages with the templates in
+// templatePaths on their default branch. Templates use the text/template
+// syntax and can refer to {{.Login}} and {{.ContributingURL}}.
+package welcome
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"text/template"
+
+	"github.com/google/go-github/github"
+	"github.com/mtraver/gaelog"
+	"github.com/octo/ghbot/client"
+	"github.com/octo/ghbot/event"
+)
+
+const contributingURL = "https://github.com/collectd/collectd/blob/main/docs/CONTRIBUTING.md"
+
+// firstTimeAssociations are the author associations Github reports for
+// first-time contributors.
+var firstTimeAssociations = map[string]bool{
+	"FIRST_TIMER":            true,
+	"FIRST_TIME_CONTRIBUTOR": true,
+}
+
+// templatePaths are the files in the repository overriding defaultTemplates.
+var templatePaths = map[string]string{
+	"pull request": ".github/welcome/pull_request.md",
+	"issue":        ".github/welcome/issue.md",
+}
+
+// defaultTemplates hold the welcome message for pull requests and issues.
+var defaultTemplates = map[string]*template.Template{
+	"pull request": template.Must(template.New("pull request").Parse(`Hi @{{.Login}}, thank you for your first pull request to collectd! 🎉
+
+A few things will help getting it merged:
+
+* Add a [ChangeLog entry]({{.ContributingURL}}#changelog) like ` + "`ChangeLog: Foo plugin: Did a thing.`" + ` to the description.
+* A maintainer will set one of the [labels]({{.ContributingURL}}#labels) "Feature", "Fix" or "Maintenance". Prefixing the title with "feat:" or "fix:" lets the bot guess it.
+* Format your code with clang-format, see the [contributing guidelines]({{.ContributingURL}}). If it isn't, the bot will suggest the formatted version.
+
+The bot's checks below tell you if anything is missing. Thanks again!`)),
+	"issue": template.Must(template.New("issue").Parse(`Hi @{{.Login}}, thank you for opening your first issue! 🎉
+
+Please make sure to include the collectd version, your platform and the relevant parts of your configuration. If you plan to fix this yourself, please read our [contributing guidelines]({{.ContributingURL}}).`)),
+}
+
+type data struct {
+	Login           string
+	ContributingURL string
+}
+
+func init() {
+	event.PullRequestHandler("welcome", processPullRequest)
+	event.IssuesHandler("welcome", processIssues)
+}
+
+func processPullRequest(ctx context.Context, e *github.PullRequestEvent) error {
+	pr := e.GetPullRequest()
+	if e.GetAction() != "opened" || !firstTimeAssociations[pr.GetAuthorAssociation()] {
+		return nil
+	}
+
+	c, err := client.New(ctx, client.DefaultOwner, client.DefaultRepo)
+	if err != nil {
+		return err
+	}
+
+	issue, err := c.WrapPR(pr).Issue(ctx)
+	if err != nil {
+		return err
+	}
+
+	return welcome(ctx, c, issue, "pull request", pr.GetUser().GetLogin())
+}
+
+func processIssues(ctx context.Context, e *github.IssuesEvent) error {
+	if e.GetAction() != "opened" || e.GetIssue().IsPullRequest() {
+		return nil
+	}
+
+	c, err := client.New(ctx, client.DefaultOwner, client.DefaultRepo)
+	if err != nil {
+		return err
+	}
+
+	// The Issue type doesn't expose the author association, so we check
+	// whether this is the author's only issue instead.
+	login := e.GetIssue().GetUser().GetLogin()
+	n, err := c.IssuesBy(ctx, login, 2)
+	if err != nil {
+		return err
+	}
+	if n > 1 {
+		return nil
+	}
+
+	return welcome(ctx, c, c.WrapIssue(e.GetIssue()), "issue", login)
+}
+
+// loadTemplate returns the repository's template for kind, or the default
+// template if the repository doesn't have one or it is broken.
+func loadTemplate(ctx context.Context, c *client.Client, kind string) *template.Template {
+	path := templatePaths[kind]
+	content, err := c.FileContent(ctx, path, "")
+	if errors.Is(err, os.ErrNotExist) {
+		return defaultTemplates[kind]
+	}
+	if err != nil {
+		gaelog.Warningf(ctx, "welcome: %v", err)
+		return defaultTemplates[kind]
+	}
+
+	tmpl, err := template.New(kind).Parse(content)
+	if err != nil {
+		gaelog.Warningf(ctx, "welcome: %s: %v", path, err)
+		return defaultTemplates[kind]
+	}
+	return tmpl
+}
+
+// render returns the welcome message for login.
+func render(tmpl *template.Template, login string) (string, error) {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data{Login: login, ContributingURL: contributingURL}); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// welcome posts the welcome message, or updates a previously posted one.
+func welcome(ctx context.Context, c *client.Client, issue *client.Issue, kind, login string) error {
+	body, err := render(loadTemplate(ctx, c, kind), login)
+	if err != nil {
+		return err
+	}
+
+	gaelog.Infof(ctx, "welcome: greeting %s on %v", login, issue)
+	return issue.SetStickyComment(ctx, "welcome", body)
+}
@@ -0,0 +1,37 @@
+package welcome
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestRender(t *testing.T) {
+	for kind, tmpl := range defaultTemplates {
+		got, err := render(tmpl, "octocat")
+		if err != nil {
+			t.Errorf("render(%q) = %v", kind, err)
+			continue
+		}
+		if !strings.Contains(got, "@octocat") {
+			t.Errorf("render(%q) does not mention the user", kind)
+		}
+	}
+
+	tmpl := template.Must(template.New("custom").Parse("Welcome, {{.Login}}! See {{.ContributingURL}}."))
+	got, err := render(tmpl, "octocat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Welcome, octocat! See " + contributingURL + "."; got != want {
+		t.Errorf("render(custom) = %q, want %q", got, want)
+	}
+}
+
+func TestTemplatePaths(t *testing.T) {
+	for kind := range defaultTemplates {
+		if _, ok := templatePaths[kind]; !ok {
+			t.Errorf("templatePaths[%q] is not set", kind)
+		}
+	}
+}
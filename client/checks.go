@@ -3,10 +3,18 @@ package client
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/google/go-github/github"
 )
 
+const (
+	ConclusionSuccess        = "success"
+	ConclusionFailure        = "failure"
+	ConclusionNeutral        = "neutral"
+	ConclusionActionRequired = "action_required"
+)
+
 func (c *Client) CheckRuns(ctx context.Context, ref string) ([]*github.CheckRun, error) {
 	opts := github.ListCheckRunsOptions{}
 	resp, _, err := c.Client.Checks.ListCheckRunsForRef(ctx, c.owner, c.repo, ref, &opts)
@@ -16,3 +24,74 @@ func (c *Client) CheckRuns(ctx context.Context, ref string) ([]*github.CheckRun,
 
 	return resp.CheckRuns, nil
 }
+
+// Check is the result of a check. Creating check runs requires authenticating
+// as a Github App, so checks are published as commit statuses, with the
+// details in a sticky comment.
+type Check struct {
+	Name string
+	// Ref is the commit the status is set on. Defaults to the head of the
+	// pull request.
+	Ref        string
+	Conclusion string
+	DetailsURL string
+
+	// Title is used as the status description.
+	Title   string
+	Summary string
+	Text    string
+}
+
+// checkState returns the commit status state for conclusion. Commit statuses
+// have no neutral state, so neutral checks are reported as pending.
+func checkState(conclusion string) string {
+	switch conclusion {
+	case ConclusionSuccess:
+		return StatusSuccess
+	case ConclusionNeutral:
+		return StatusPending
+	default:
+		return StatusFailure
+	}
+}
+
+// actionable returns true if a check with conclusion requires the author to do
+// something.
+func actionable(conclusion string) bool {
+	return conclusion == ConclusionFailure || conclusion == ConclusionActionRequired
+}
+
+// SetCheck sets the status check.Name on check.Ref. If the check failed,
+// check.Summary and check.Text are posted as a sticky comment, which is
+// only edited when its text changes. Otherwise the comment is removed again.
+func (pr *PR) SetCheck(ctx context.Context, check Check) error {
+	ref := check.Ref
+	if ref == "" {
+		ref = pr.GetHead().GetSHA()
+	}
+
+	c := pr.client
+	if err := c.SetStatus(ctx, check.Name, checkState(check.Conclusion), check.Title, check.DetailsURL, ref); err != nil {
+		return err
+	}
+
+	issue, err := pr.Issue(ctx)
+	if err != nil {
+		return err
+	}
+
+	var body string
+	if actionable(check.Conclusion) {
+		var b strings.Builder
+		fmt.Fprintf(&b, "**%s:** %s\n", check.Name, check.Title)
+		for _, s := range []string{check.Summary, check.Text} {
+			if s != "" {
+				fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(s))
+			}
+		}
+		body = b.String()
+	}
+
+	const maxBodyLen = 65000
+	return issue.SetStickyComment(ctx, "check/"+check.Name, trimLength(body, maxBodyLen))
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// ConflictError is returned when changes cannot be applied because files they
+// modify have been changed on the target, too.
+type ConflictError struct {
+	Files []string
+}
+
+func (e *ConflictError) Error() string {
+	return "conflicting files: " + strings.Join(e.Files, ", ")
+}
+
+// errMergeCommit is returned when trying to cherry-pick a merge commit.
+var errMergeCommit = errors.New("cannot cherry-pick merge commits")
+
+// tree returns all non-tree entries of the tree of commit, keyed by path.
+func (c *Client) tree(ctx context.Context, commit *github.Commit) (map[string]github.TreeEntry, error) {
+	t, _, err := c.Git.GetTree(ctx, c.owner, c.repo, commit.GetTree().GetSHA(), true)
+	if err != nil {
+		return nil, fmt.Errorf("Git.GetTree(%q): %w", commit.GetTree().GetSHA(), err)
+	}
+	if t.GetTruncated() {
+		return nil, fmt.Errorf("tree of %s is too large", commit.GetSHA())
+	}
+
+	ret := make(map[string]github.TreeEntry)
+	for _, e := range t.Entries {
+		if e.GetType() == "tree" {
+			continue
+		}
+		ret[e.GetPath()] = e
+	}
+	return ret, nil
+}
+
+func entrySHA(tree map[string]github.TreeEntry, path string) string {
+	e, ok := tree[path]
+	if !ok {
+		return ""
+	}
+	return e.GetSHA()
+}
+
+// CherryPick applies the commits shas, in order, on top of the commit onto and
+// returns the SHA of the last new commit. No reference is updated. This uses
+// the git data API and cannot merge changes: if a file changed by one of the
+// commits differs between the commit's parent and the target, a
+// *ConflictError listing all such files is returned.
+func (c *Client) CherryPick(ctx context.Context, shas []string, onto string) (string, error) {
+	parent, _, err := c.Git.GetCommit(ctx, c.owner, c.repo, onto)
+	if err != nil {
+		return "", fmt.Errorf("Git.GetCommit(%q): %w", onto, err)
+	}
+
+	target, err := c.tree(ctx, parent)
+	if err != nil {
+		return "", err
+	}
+
+	var conflicts []string
+	for _, sha := range shas {
+		commit, _, err := c.Git.GetCommit(ctx, c.owner, c.repo, sha)
+		if err != nil {
+			return "", fmt.Errorf("Git.GetCommit(%q): %w", sha, err)
+		}
+		if len(commit.Parents) != 1 {
+			return "", fmt.Errorf("%s: %w", sha, errMergeCommit)
+		}
+
+		origParent, _, err := c.Git.GetCommit(ctx, c.owner, c.repo, commit.Parents[0].GetSHA())
+		if err != nil {
+			return "", fmt.Errorf("Git.GetCommit(%q): %w", commit.Parents[0].GetSHA(), err)
+		}
+
+		before, err := c.tree(ctx, origParent)
+		if err != nil {
+			return "", err
+		}
+		after, err := c.tree(ctx, commit)
+		if err != nil {
+			return "", err
+		}
+
+		paths := map[string]bool{}
+		for p := range before {
+			paths[p] = true
+		}
+		for p := range after {
+			paths[p] = true
+		}
+
+		for p := range paths {
+			if entrySHA(before, p) == entrySHA(after, p) || entrySHA(target, p) == entrySHA(after, p) {
+				continue
+			}
+			if entrySHA(target, p) != entrySHA(before, p) {
+				conflicts = append(conflicts, p)
+				continue
+			}
+
+			if e, ok := after[p]; ok {
+				target[p] = e
+			} else {
+				delete(target, p)
+			}
+		}
+		if len(conflicts) != 0 {
+			sort.Strings(conflicts)
+			return "", &ConflictError{Files: conflicts}
+		}
+
+		var entries []github.TreeEntry
+		for _, e := range target {
+			entries = append(entries, github.TreeEntry{
+				Path: e.Path,
+				Mode: e.Mode,
+				Type: e.Type,
+				SHA:  e.SHA,
+			})
+		}
+
+		tree, _, err := c.Git.CreateTree(ctx, c.owner, c.repo, "", entries)
+		if err != nil {
+			return "", fmt.Errorf("Git.CreateTree(): %w", err)
+		}
+
+		parent, _, err = c.Git.CreateCommit(ctx, c.owner, c.repo, &github.Commit{
+			Message: commit.Message,
+			Author:  commit.Author,
+			Tree:    tree,
+			Parents: []github.Commit{{SHA: parent.SHA}},
+		})
+		if err != nil {
+			return "", fmt.Errorf("Git.CreateCommit(): %w", err)
+		}
+	}
+
+	return parent.GetSHA(), nil
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"contrib.go.opencensus.io/exporter/stackdriver/propagation"
 	"github.com/google/go-github/github"
@@ -143,6 +144,25 @@ func (c *Client) CreateStatus(ctx context.Context, name, state, desc, url, ref s
 	return err
 }
 
+// SetStatus creates the status name on ref, unless its latest state and
+// description are already the same. This avoids triggering status events, and
+// handlers reacting to them, for no reason.
+func (c *Client) SetStatus(ctx context.Context, name, state, desc, url, ref string) error {
+	const maxDescLen = 140
+
+	combined, _, err := c.Repositories.GetCombinedStatus(ctx, c.owner, c.repo, ref, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return fmt.Errorf("Repositories.GetCombinedStatus(%q): %w", ref, err)
+	}
+	for _, s := range combined.Statuses {
+		if s.GetContext() == name && s.GetState() == state && s.GetDescription() == trimLength(desc, maxDescLen) {
+			return nil
+		}
+	}
+
+	return c.CreateStatus(ctx, name, state, desc, url, ref)
+}
+
 func (c *Client) Milestones(ctx context.Context) (map[string]int, error) {
 	var (
 		ret  = make(map[string]int)
@@ -168,6 +188,298 @@ func (c *Client) Milestones(ctx context.Context) (map[string]int, error) {
 	return ret, nil
 }
 
+// TeamMembers returns the logins of the members of team, which is given as
+// "org/team-slug".
+func (c *Client) TeamMembers(ctx context.Context, team string) ([]string, error) {
+	org, slug, ok := strings.Cut(team, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid team %q, want \"org/team-slug\"", team)
+	}
+
+	var (
+		id       int64
+		teamOpts github.ListOptions
+	)
+	for id == 0 {
+		teams, res, err := c.Teams.ListTeams(ctx, org, &teamOpts)
+		if err != nil {
+			return nil, fmt.Errorf("Teams.ListTeams(%q): %w", org, err)
+		}
+
+		for _, t := range teams {
+			if t.GetSlug() == slug {
+				id = t.GetID()
+			}
+		}
+
+		if res.NextPage == 0 {
+			break
+		}
+		teamOpts.Page = res.NextPage
+	}
+	if id == 0 {
+		return nil, fmt.Errorf("team %q: %w", team, os.ErrNotExist)
+	}
+
+	var (
+		ret  []string
+		opts github.TeamListTeamMembersOptions
+	)
+	for {
+		users, res, err := c.Teams.ListTeamMembers(ctx, id, &opts)
+		if err != nil {
+			return nil, fmt.Errorf("Teams.ListTeamMembers(%q): %w", team, err)
+		}
+
+		for _, u := range users {
+			ret = append(ret, u.GetLogin())
+		}
+
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+
+	return ret, nil
+}
+
+// FileContent returns the content of the file at path in the repository at
+// ref. If the file does not exist, os.ErrNotExist is returned.
+func (c *Client) FileContent(ctx context.Context, path, ref string) (string, error) {
+	f, _, res, err := c.Repositories.GetContents(ctx, c.owner, c.repo, path, &github.RepositoryContentGetOptions{
+		Ref: ref,
+	})
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		return "", os.ErrNotExist
+	}
+	if err != nil {
+		return "", fmt.Errorf("Repositories.GetContents(%q, %q): %w", path, ref, err)
+	}
+	if f == nil {
+		return "", fmt.Errorf("%q is not a file", path)
+	}
+
+	return f.GetContent()
+}
+
+// OpenIssues returns all open issues and pull requests, least recently
+// updated first.
+func (c *Client) OpenIssues(ctx context.Context) ([]*Issue, error) {
+	opts := &github.IssueListByRepoOptions{
+		State:     "open",
+		Sort:      "updated",
+		Direction: "asc",
+	}
+
+	var ret []*Issue
+	for {
+		issues, res, err := c.Issues.ListByRepo(ctx, c.owner, c.repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("Issues.ListByRepo(open): %w", err)
+		}
+
+		for _, i := range issues {
+			ret = append(ret, c.WrapIssue(i))
+		}
+
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+
+	return ret, nil
+}
+
+// OpenPRs returns all open pull requests, least recently updated first.
+func (c *Client) OpenPRs(ctx context.Context) ([]*PR, error) {
+	opts := &github.PullRequestListOptions{
+		State:     "open",
+		Sort:      "updated",
+		Direction: "asc",
+	}
+
+	var ret []*PR
+	for {
+		prs, res, err := c.PullRequests.List(ctx, c.owner, c.repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("PullRequests.List(open): %w", err)
+		}
+
+		for _, pr := range prs {
+			ret = append(ret, c.WrapPR(pr))
+		}
+
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+
+	return ret, nil
+}
+
+// IssuesBy returns the number of issues and pull requests, open or closed,
+// created by login. Counting stops at max.
+func (c *Client) IssuesBy(ctx context.Context, login string, max int) (int, error) {
+	opts := &github.IssueListByRepoOptions{
+		Creator: login,
+		State:   "all",
+	}
+
+	n := 0
+	for {
+		issues, res, err := c.Issues.ListByRepo(ctx, c.owner, c.repo, opts)
+		if err != nil {
+			return 0, fmt.Errorf("Issues.ListByRepo(creator %q): %w", login, err)
+		}
+
+		n += len(issues)
+		if n >= max || res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+
+	return n, nil
+}
+
+// Login returns the login of the user the client is authenticated as.
+func (c *Client) Login(ctx context.Context) (string, error) {
+	u, _, err := c.Users.Get(ctx, "")
+	if err != nil {
+		return "", fmt.Errorf("Users.Get(): %w", err)
+	}
+	return u.GetLogin(), nil
+}
+
+// ListDir returns the names of the entries of the directory at path in the
+// repository at ref.
+func (c *Client) ListDir(ctx context.Context, path, ref string) ([]string, error) {
+	_, dir, _, err := c.Repositories.GetContents(ctx, c.owner, c.repo, path, &github.RepositoryContentGetOptions{
+		Ref: ref,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Repositories.GetContents(%q, %q): %w", path, ref, err)
+	}
+
+	var ret []string
+	for _, e := range dir {
+		ret = append(ret, e.GetName())
+	}
+	return ret, nil
+}
+
+// BranchSHA returns the SHA of the commit branch points to. If the branch does
+// not exist, os.ErrNotExist is returned.
+func (c *Client) BranchSHA(ctx context.Context, branch string) (string, error) {
+	ref, res, err := c.Git.GetRef(ctx, c.owner, c.repo, "heads/"+branch)
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		return "", os.ErrNotExist
+	}
+	if err != nil {
+		return "", fmt.Errorf("Git.GetRef(%q): %w", branch, err)
+	}
+
+	return ref.GetObject().GetSHA(), nil
+}
+
+// Branches returns the names of all branches of the repository.
+func (c *Client) Branches(ctx context.Context) ([]string, error) {
+	var (
+		opts = &github.ListOptions{}
+		ret  []string
+	)
+
+	for {
+		branches, res, err := c.Repositories.ListBranches(ctx, c.owner, c.repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("Repositories.ListBranches(): %w", err)
+		}
+
+		for _, b := range branches {
+			ret = append(ret, b.GetName())
+		}
+
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+
+	return ret, nil
+}
+
+// CreateBranch creates a new branch pointing to the commit sha.
+func (c *Client) CreateBranch(ctx context.Context, branch, sha string) error {
+	_, _, err := c.Git.CreateRef(ctx, c.owner, c.repo, &github.Reference{
+		Ref: github.String("refs/heads/" + branch),
+		Object: &github.GitObject{
+			Type: github.String("commit"),
+			SHA:  github.String(sha),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Git.CreateRef(%q): %w", branch, err)
+	}
+	return nil
+}
+
+// PRByHead returns the pull request, open or closed, that merges the branch
+// head into base. If there is no such pull request, os.ErrNotExist is
+// returned.
+func (c *Client) PRByHead(ctx context.Context, base, head string) (*PR, error) {
+	prs, _, err := c.PullRequests.List(ctx, c.owner, c.repo, &github.PullRequestListOptions{
+		State: "all",
+		Head:  c.owner + ":" + head,
+		Base:  base,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("PullRequests.List(%q, %q): %w", base, head, err)
+	}
+	if len(prs) == 0 {
+		return nil, os.ErrNotExist
+	}
+
+	return c.WrapPR(prs[0]), nil
+}
+
+// CreatePR opens a pull request to merge head into base.
+func (c *Client) CreatePR(ctx context.Context, base, head, title, body string) (*PR, error) {
+	pr, _, err := c.PullRequests.Create(ctx, c.owner, c.repo, &github.NewPullRequest{
+		Title: github.String(title),
+		Head:  github.String(head),
+		Base:  github.String(base),
+		Body:  github.String(body),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("PullRequests.Create(%q, %q): %w", base, head, err)
+	}
+
+	return c.WrapPR(pr), nil
+}
+
+// Permission returns the permission level login has on the repository, one of
+// "admin", "write", "read" and "none".
+func (c *Client) Permission(ctx context.Context, login string) (string, error) {
+	p, _, err := c.Repositories.GetPermissionLevel(ctx, c.owner, c.repo, login)
+	if err != nil {
+		return "", fmt.Errorf("Repositories.GetPermissionLevel(%q): %w", login, err)
+	}
+
+	return p.GetPermission(), nil
+}
+
+// React adds a reaction, e.g. "+1" or "eyes", to an issue comment.
+func (c *Client) React(ctx context.Context, commentID int64, content string) error {
+	_, _, err := c.Reactions.CreateIssueCommentReaction(ctx, c.owner, c.repo, commentID, content)
+	if err != nil {
+		return fmt.Errorf("Reactions.CreateIssueCommentReaction(%d, %q): %w", commentID, content, err)
+	}
+	return nil
+}
+
 func (c *Client) FormatUser(ctx context.Context, login string) string {
 	u, _, err := c.Users.Get(ctx, login)
 	if err != nil || u.GetName() == "" {
@@ -30,3 +30,26 @@ func TestTrimLength(t *testing.T) {
 		}
 	}
 }
+
+func TestMergeGroupRefRE(t *testing.T) {
+	cases := []struct {
+		ref  string
+		want string
+	}{
+		{"refs/heads/gh-readonly-queue/main/pr-4211-6d1b3ac5c1fa0b0a25bfac7cfc8e7ab5f1c3d1e2", "4211"},
+		{"gh-readonly-queue/collectd-5.12/pr-17-abcdef", "17"},
+		{"refs/heads/main", ""},
+		{"refs/heads/gh-readonly-queue/main/pr-0-abcdef", ""},
+	}
+
+	for _, tc := range cases {
+		var got string
+		if m := mergeGroupRefRE.FindStringSubmatch(tc.ref); m != nil {
+			got = m[1]
+		}
+
+		if got != tc.want {
+			t.Errorf("mergeGroupRefRE.FindStringSubmatch(%q) = %q, want %q", tc.ref, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// GraphQL sends query to Github's GraphQL API and decodes the "data" field of
+// the response into data. Some information, such as whether a review thread
+// has been resolved, is only available via this API.
+func (c *Client) GraphQL(ctx context.Context, query string, vars map[string]interface{}, data interface{}) error {
+	req, err := c.Client.NewRequest("POST", "graphql", &graphQLRequest{
+		Query:     query,
+		Variables: vars,
+	})
+	if err != nil {
+		return err
+	}
+
+	var res graphQLResponse
+	if _, err := c.Client.Do(ctx, req, &res); err != nil {
+		return fmt.Errorf("GraphQL: %w", err)
+	}
+
+	if len(res.Errors) != 0 {
+		var msgs []string
+		for _, e := range res.Errors {
+			msgs = append(msgs, e.Message)
+		}
+		return fmt.Errorf("GraphQL: %s", strings.Join(msgs, "; "))
+	}
+
+	if data == nil {
+		return nil
+	}
+	return json.Unmarshal(res.Data, data)
+}
@@ -51,3 +51,105 @@ func (i *Issue) AddLabel(ctx context.Context, label string) error {
 	}
 	return nil
 }
+
+// RemoveLabel removes label from the issue.
+func (i *Issue) RemoveLabel(ctx context.Context, label string) error {
+	c := i.client
+	_, err := c.Issues.RemoveLabelForIssue(ctx, c.owner, c.repo, i.GetNumber(), label)
+	if err != nil {
+		return fmt.Errorf("RemoveLabelForIssue(#%d, %q): %w", i.GetNumber(), label, err)
+	}
+	return nil
+}
+
+// LabeledBy returns the login of the user who most recently added each label
+// to the issue, keyed by label name.
+func (i *Issue) LabeledBy(ctx context.Context) (map[string]string, error) {
+	var (
+		c    = i.client
+		opts = &github.ListOptions{}
+		ret  = map[string]string{}
+	)
+
+	for {
+		events, res, err := c.Issues.ListIssueEvents(ctx, c.owner, c.repo, i.Number(), opts)
+		if err != nil {
+			return nil, fmt.Errorf("Issues.ListIssueEvents(#%d): %w", i.Number(), err)
+		}
+
+		// Events are returned in chronological order.
+		for _, e := range events {
+			if e.GetEvent() == "labeled" {
+				ret[e.GetLabel().GetName()] = e.GetActor().GetLogin()
+			}
+		}
+
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+
+	return ret, nil
+}
+
+// Comments returns all comments on the issue.
+func (i *Issue) Comments(ctx context.Context) ([]*github.IssueComment, error) {
+	var (
+		c    = i.client
+		opts = &github.IssueListCommentsOptions{}
+		ret  []*github.IssueComment
+	)
+
+	for {
+		comments, res, err := c.Issues.ListComments(ctx, c.owner, c.repo, i.Number(), opts)
+		if err != nil {
+			return nil, fmt.Errorf("Issues.ListComments(#%d): %w", i.Number(), err)
+		}
+
+		ret = append(ret, comments...)
+
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+
+	return ret, nil
+}
+
+// Comment adds a comment to the issue.
+func (i *Issue) Comment(ctx context.Context, body string) error {
+	c := i.client
+	_, _, err := c.Issues.CreateComment(ctx, c.owner, c.repo, i.Number(), &github.IssueComment{
+		Body: github.String(body),
+	})
+	if err != nil {
+		return fmt.Errorf("Issues.CreateComment(#%d): %w", i.Number(), err)
+	}
+	return nil
+}
+
+// EditComment replaces the body of the comment with the ID id.
+func (i *Issue) EditComment(ctx context.Context, id int64, body string) error {
+	c := i.client
+	_, _, err := c.Issues.EditComment(ctx, c.owner, c.repo, id, &github.IssueComment{
+		Body: github.String(body),
+	})
+	if err != nil {
+		return fmt.Errorf("Issues.EditComment(#%d, %d): %w", i.Number(), id, err)
+	}
+	return nil
+}
+
+// Close closes the issue.
+func (i *Issue) Close(ctx context.Context) error {
+	c := i.client
+	_, _, err := c.Issues.Edit(ctx, c.owner, c.repo, i.Number(), &github.IssueRequest{
+		State: github.String("closed"),
+	})
+	if err != nil {
+		return fmt.Errorf("Issues.Edit(#%d, {State: closed}): %w", i.Number(), err)
+	}
+	return nil
+}
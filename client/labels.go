@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/github"
+)
+
+// Labels returns all labels of the repository.
+func (c *Client) Labels(ctx context.Context) ([]*github.Label, error) {
+	var (
+		opts = &github.ListOptions{}
+		ret  []*github.Label
+	)
+
+	for {
+		labels, res, err := c.Issues.ListLabels(ctx, c.owner, c.repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("Issues.ListLabels(): %w", err)
+		}
+
+		ret = append(ret, labels...)
+
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+
+	return ret, nil
+}
+
+// CreateLabel creates a repository label.
+func (c *Client) CreateLabel(ctx context.Context, label *github.Label) error {
+	_, _, err := c.Issues.CreateLabel(ctx, c.owner, c.repo, label)
+	if err != nil {
+		return fmt.Errorf("Issues.CreateLabel(%q): %w", label.GetName(), err)
+	}
+	return nil
+}
+
+// EditLabel updates the label called name. Setting a different name in label
+// renames the label, keeping it on all issues.
+func (c *Client) EditLabel(ctx context.Context, name string, label *github.Label) error {
+	_, _, err := c.Issues.EditLabel(ctx, c.owner, c.repo, name, label)
+	if err != nil {
+		return fmt.Errorf("Issues.EditLabel(%q): %w", name, err)
+	}
+	return nil
+}
+
+// DeleteLabel deletes a label from the repository and all issues.
+func (c *Client) DeleteLabel(ctx context.Context, name string) error {
+	_, err := c.Issues.DeleteLabel(ctx, c.owner, c.repo, name)
+	if err != nil {
+		return fmt.Errorf("Issues.DeleteLabel(%q): %w", name, err)
+	}
+	return nil
+}
+
+// LabeledIssues returns all issues and pull requests, open or closed, that
+// have label.
+func (c *Client) LabeledIssues(ctx context.Context, label string) ([]*Issue, error) {
+	opts := &github.IssueListByRepoOptions{
+		Labels: []string{label},
+		State:  "all",
+	}
+
+	var ret []*Issue
+	for {
+		issues, res, err := c.Issues.ListByRepo(ctx, c.owner, c.repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("Issues.ListByRepo(label %q): %w", label, err)
+		}
+
+		for _, i := range issues {
+			ret = append(ret, c.WrapIssue(i))
+		}
+
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+
+	return ret, nil
+}
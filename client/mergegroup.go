@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/octo/ghbot/event"
+)
+
+// MergeGroupHandler returns a handler for MergeGroup events. When checks are
+// requested for a merge group, process is called with the pull request the
+// group was created for and the group's head commit, so that actions can
+// report the pull request's status on that commit.
+func MergeGroupHandler(process func(ctx context.Context, c *Client, pr *PR, ref string) error) func(context.Context, *event.MergeGroupEvent) error {
+	return func(ctx context.Context, e *event.MergeGroupEvent) error {
+		if e.GetAction() != "checks_requested" {
+			return nil
+		}
+
+		c, err := New(ctx, DefaultOwner, DefaultRepo)
+		if err != nil {
+			return err
+		}
+
+		mg := e.GetMergeGroup()
+		pr, err := c.MergeGroupPR(ctx, mg.GetHeadRef())
+		if err != nil {
+			return fmt.Errorf("MergeGroupPR(%q): %w", mg.GetHeadRef(), err)
+		}
+
+		return process(ctx, c, pr, mg.GetHeadSHA())
+	}
+}
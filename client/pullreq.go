@@ -5,7 +5,11 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
-	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/google/go-github/github"
 	"github.com/octo/retry"
@@ -56,11 +60,15 @@ func (pr *PR) Mergeable(ctx context.Context) (bool, error) {
 	return *pr.PullRequest.Mergeable, nil
 }
 
-// Merge merges the pull request.
-func (pr *PR) Merge(ctx context.Context, title, msg string) error {
+// Merge merges the pull request using method, which is one of "merge",
+// "squash" and "rebase". The head SHA the pull request had when it was
+// fetched is passed along, so that a push racing the merge causes the merge to
+// fail rather than merging unreviewed commits.
+func (pr *PR) Merge(ctx context.Context, method, title, msg string) error {
 	opts := &github.PullRequestOptions{
 		CommitTitle: title,
-		MergeMethod: "merge",
+		SHA:         pr.GetHead().GetSHA(),
+		MergeMethod: method,
 	}
 
 	res, _, err := pr.client.PullRequests.Merge(ctx, pr.client.owner, pr.client.repo, pr.Number(), msg, opts)
@@ -69,8 +77,7 @@ func (pr *PR) Merge(ctx context.Context, title, msg string) error {
 	}
 
 	if !res.GetMerged() {
-		log.Printf("did not merge %v: %s", pr, res.GetMessage())
-		return nil
+		return fmt.Errorf("did not merge %v: %s", pr, res.GetMessage())
 	}
 
 	return nil
@@ -124,6 +131,32 @@ func (pr *PR) Files(ctx context.Context) ([]PRFile, error) {
 	return ret, nil
 }
 
+// Changes returns all files changed by the pull request, including deleted
+// files, with their number of added and deleted lines.
+func (pr *PR) Changes(ctx context.Context) ([]*github.CommitFile, error) {
+	var (
+		c    = pr.client
+		opts = &github.ListOptions{}
+		ret  []*github.CommitFile
+	)
+
+	for {
+		files, res, err := c.PullRequests.ListFiles(ctx, c.owner, c.repo, pr.Number(), opts)
+		if err != nil {
+			return nil, fmt.Errorf("PullRequests.ListFiles(%d): %w", pr.Number(), err)
+		}
+
+		ret = append(ret, files...)
+
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+
+	return ret, nil
+}
+
 func (pr *PR) Blob(ctx context.Context, sha string) (string, error) {
 	repo := pr.PullRequest.Head.Repo
 
@@ -162,3 +195,305 @@ func (pr *PR) Reviews(ctx context.Context) ([]*github.PullRequestReview, error)
 
 	return ret, nil
 }
+
+// Commits returns the commits that are part of this PR.
+func (pr *PR) Commits(ctx context.Context) ([]*github.RepositoryCommit, error) {
+	var (
+		opts = &github.ListOptions{}
+		ret  []*github.RepositoryCommit
+	)
+
+	for {
+		commits, res, err := pr.client.PullRequests.ListCommits(ctx, pr.client.owner, pr.client.repo, pr.Number(), opts)
+		if err != nil {
+			return nil, fmt.Errorf("PullRequests.ListCommits(%v): %w", pr, err)
+		}
+
+		ret = append(ret, commits...)
+
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+
+	return ret, nil
+}
+
+// ReviewThread is a conversation on a pull request's diff.
+type ReviewThread struct {
+	IsResolved bool
+	IsOutdated bool
+	// Author and URL refer to the first comment of the thread.
+	Author string
+	URL    string
+}
+
+const reviewThreadsQuery = `query($owner: String!, $repo: String!, $number: Int!, $cursor: String) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      reviewThreads(first: 100, after: $cursor) {
+        pageInfo { hasNextPage endCursor }
+        nodes {
+          isResolved
+          isOutdated
+          comments(first: 1) { nodes { author { login } url } }
+        }
+      }
+    }
+  }
+}`
+
+// ReviewThreads returns the review threads of this PR, including their
+// resolution state.
+func (pr *PR) ReviewThreads(ctx context.Context) ([]ReviewThread, error) {
+	var (
+		ret  []ReviewThread
+		vars = map[string]interface{}{
+			"owner":  pr.client.owner,
+			"repo":   pr.client.repo,
+			"number": pr.Number(),
+		}
+	)
+
+	for {
+		var data struct {
+			Repository struct {
+				PullRequest struct {
+					ReviewThreads struct {
+						PageInfo struct {
+							HasNextPage bool
+							EndCursor   string
+						}
+						Nodes []struct {
+							IsResolved bool
+							IsOutdated bool
+							Comments   struct {
+								Nodes []struct {
+									Author struct {
+										Login string
+									}
+									URL string
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+		if err := pr.client.GraphQL(ctx, reviewThreadsQuery, vars, &data); err != nil {
+			return nil, fmt.Errorf("reviewThreads(%v): %w", pr, err)
+		}
+
+		threads := data.Repository.PullRequest.ReviewThreads
+		for _, n := range threads.Nodes {
+			t := ReviewThread{
+				IsResolved: n.IsResolved,
+				IsOutdated: n.IsOutdated,
+			}
+			if len(n.Comments.Nodes) != 0 {
+				t.Author = n.Comments.Nodes[0].Author.Login
+				t.URL = n.Comments.Nodes[0].URL
+			}
+			ret = append(ret, t)
+		}
+
+		if !threads.PageInfo.HasNextPage {
+			break
+		}
+		vars["cursor"] = threads.PageInfo.EndCursor
+	}
+
+	return ret, nil
+}
+
+// BehindBy returns the number of commits on the base branch that are not
+// included in the head of the pull request.
+func (pr *PR) BehindBy(ctx context.Context) (int, error) {
+	cmp, _, err := pr.client.Repositories.CompareCommits(ctx, pr.client.owner, pr.client.repo, pr.GetBase().GetRef(), pr.GetHead().GetSHA())
+	if err != nil {
+		return 0, fmt.Errorf("CompareCommits(%q, %q): %w", pr.GetBase().GetRef(), pr.GetHead().GetSHA(), err)
+	}
+
+	return cmp.GetBehindBy(), nil
+}
+
+// UpdateBranch merges the base branch into the head branch of the pull
+// request, using Github's "Update branch" functionality.
+func (pr *PR) UpdateBranch(ctx context.Context) error {
+	u := fmt.Sprintf("repos/%v/%v/pulls/%d/update-branch", pr.client.owner, pr.client.repo, pr.Number())
+	req, err := pr.client.NewRequest("PUT", u, map[string]string{
+		"expected_head_sha": pr.GetHead().GetSHA(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := pr.client.Do(ctx, req, nil); err != nil {
+		return fmt.Errorf("UpdateBranch(%v): %w", pr, err)
+	}
+	return nil
+}
+
+var mergeGroupRefRE = regexp.MustCompile(`^(?:refs/heads/)?gh-readonly-queue/.+/pr-([1-9][0-9]*)-[0-9a-f]+$`)
+
+// MergeGroupPR returns the pull request a merge group was created for. headRef
+// is the merge group's head ref, e.g.
+// "refs/heads/gh-readonly-queue/main/pr-123-<sha>". If headRef is not a merge
+// queue ref, os.ErrNotExist is returned.
+func (c *Client) MergeGroupPR(ctx context.Context, headRef string) (*PR, error) {
+	m := mergeGroupRefRE.FindStringSubmatch(headRef)
+	if m == nil {
+		return nil, os.ErrNotExist
+	}
+
+	number, err := strconv.Atoi(m[1])
+	if err != nil {
+		return nil, fmt.Errorf("strconv.Atoi(%q): %w", m[1], err)
+	}
+
+	return c.PR(ctx, number)
+}
+
+const mergeQueueStateQuery = `query($owner: String!, $repo: String!, $number: Int!, $branch: String!) {
+  repository(owner: $owner, name: $repo) {
+    mergeQueue(branch: $branch) { id }
+    pullRequest(number: $number) {
+      mergeQueueEntry { position }
+      autoMergeRequest { enabledAt }
+    }
+  }
+}`
+
+// MergeQueueState is the state of a pull request with respect to Github's
+// merge queue.
+type MergeQueueState struct {
+	// Enabled is true if the base branch uses Github's merge queue.
+	Enabled bool
+	// Position is the one based position in the merge queue, or zero if the
+	// pull request is not queued.
+	Position int
+	// AutoMerge is true if auto-merge is enabled for the pull request.
+	AutoMerge bool
+}
+
+// MergeQueueState returns the state of the pull request with respect to
+// Github's merge queue.
+func (pr *PR) MergeQueueState(ctx context.Context) (MergeQueueState, error) {
+	var data struct {
+		Repository struct {
+			MergeQueue *struct {
+				ID string
+			}
+			PullRequest struct {
+				MergeQueueEntry *struct {
+					Position int
+				}
+				AutoMergeRequest *struct {
+					EnabledAt string
+				}
+			}
+		}
+	}
+
+	err := pr.client.GraphQL(ctx, mergeQueueStateQuery, map[string]interface{}{
+		"owner":  pr.client.owner,
+		"repo":   pr.client.repo,
+		"number": pr.Number(),
+		"branch": pr.GetBase().GetRef(),
+	}, &data)
+	if err != nil {
+		return MergeQueueState{}, fmt.Errorf("mergeQueue(%v): %w", pr, err)
+	}
+
+	var ret MergeQueueState
+	ret.Enabled = data.Repository.MergeQueue != nil
+	if e := data.Repository.PullRequest.MergeQueueEntry; e != nil {
+		ret.Position = e.Position
+	}
+	ret.AutoMerge = data.Repository.PullRequest.AutoMergeRequest != nil
+	return ret, nil
+}
+
+const enqueuePullRequestMutation = `mutation($id: ID!, $sha: GitObjectID) {
+  enqueuePullRequest(input: {pullRequestId: $id, expectedHeadOid: $sha}) {
+    mergeQueueEntry { position }
+  }
+}`
+
+// Enqueue adds the pull request to Github's merge queue and returns its
+// position in the queue. The merge queue must be enabled for the base branch.
+func (pr *PR) Enqueue(ctx context.Context) (int, error) {
+	var data struct {
+		EnqueuePullRequest struct {
+			MergeQueueEntry struct {
+				Position int
+			}
+		}
+	}
+
+	err := pr.client.GraphQL(ctx, enqueuePullRequestMutation, map[string]interface{}{
+		"id":  pr.GetNodeID(),
+		"sha": pr.GetHead().GetSHA(),
+	}, &data)
+	if err != nil {
+		return 0, fmt.Errorf("enqueuePullRequest(%v): %w", pr, err)
+	}
+
+	return data.EnqueuePullRequest.MergeQueueEntry.Position, nil
+}
+
+const enableAutoMergeMutation = `mutation($id: ID!, $method: PullRequestMergeMethod, $sha: GitObjectID) {
+  enablePullRequestAutoMerge(input: {pullRequestId: $id, mergeMethod: $method, expectedHeadOid: $sha}) {
+    clientMutationId
+  }
+}`
+
+// EnableAutoMerge enables Github's auto-merge for the pull request. Github
+// merges the pull request, or adds it to the merge queue, once all of the
+// branch's requirements are met. method is one of "merge", "squash" and
+// "rebase".
+func (pr *PR) EnableAutoMerge(ctx context.Context, method string) error {
+	err := pr.client.GraphQL(ctx, enableAutoMergeMutation, map[string]interface{}{
+		"id":     pr.GetNodeID(),
+		"method": strings.ToUpper(method),
+		"sha":    pr.GetHead().GetSHA(),
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("enablePullRequestAutoMerge(%v): %w", pr, err)
+	}
+	return nil
+}
+
+// ConflictingFiles returns the files that have been changed both by the pull
+// request and on the base branch since the branches diverged. These are the
+// files that may cause merge conflicts.
+func (pr *PR) ConflictingFiles(ctx context.Context) ([]string, error) {
+	c := pr.client
+
+	cmp, _, err := c.Repositories.CompareCommits(ctx, c.owner, c.repo, pr.GetBase().GetRef(), pr.GetHead().GetSHA())
+	if err != nil {
+		return nil, fmt.Errorf("CompareCommits(%q, %q): %w", pr.GetBase().GetRef(), pr.GetHead().GetSHA(), err)
+	}
+
+	changed := map[string]bool{}
+	for _, f := range cmp.Files {
+		changed[f.GetFilename()] = true
+	}
+
+	mergeBase := cmp.GetMergeBaseCommit().GetSHA()
+	baseCmp, _, err := c.Repositories.CompareCommits(ctx, c.owner, c.repo, mergeBase, pr.GetBase().GetRef())
+	if err != nil {
+		return nil, fmt.Errorf("CompareCommits(%q, %q): %w", mergeBase, pr.GetBase().GetRef(), err)
+	}
+
+	var ret []string
+	for _, f := range baseCmp.Files {
+		if changed[f.GetFilename()] {
+			ret = append(ret, f.GetFilename())
+		}
+	}
+	sort.Strings(ret)
+
+	return ret, nil
+}
@@ -0,0 +1,225 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/google/go-github/github"
+)
+
+// MilestoneByTitle returns the number of the milestone called title. Unlike
+// Milestones, this includes closed milestones. If no such milestone exists,
+// os.ErrNotExist is returned.
+func (c *Client) MilestoneByTitle(ctx context.Context, title string) (int, error) {
+	opts := github.MilestoneListOptions{
+		State: "all",
+	}
+
+	for {
+		ms, res, err := c.Issues.ListMilestones(ctx, c.owner, c.repo, &opts)
+		if err != nil {
+			return 0, fmt.Errorf("Issues.ListMilestones(): %w", err)
+		}
+
+		for _, m := range ms {
+			if m.GetTitle() == title {
+				return m.GetNumber(), nil
+			}
+		}
+
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+
+	return 0, os.ErrNotExist
+}
+
+// CreateMilestone creates a milestone and returns its number.
+func (c *Client) CreateMilestone(ctx context.Context, title string) (int, error) {
+	m, _, err := c.Issues.CreateMilestone(ctx, c.owner, c.repo, &github.Milestone{
+		Title: github.String(title),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("Issues.CreateMilestone(%q): %w", title, err)
+	}
+	return m.GetNumber(), nil
+}
+
+// MilestoneIssues returns the issues and pull requests of a milestone in
+// state, which is "open", "closed" or "all".
+func (c *Client) MilestoneIssues(ctx context.Context, milestone int, state string) ([]*Issue, error) {
+	opts := &github.IssueListByRepoOptions{
+		Milestone: strconv.Itoa(milestone),
+		State:     state,
+	}
+
+	var ret []*Issue
+	for {
+		issues, res, err := c.Issues.ListByRepo(ctx, c.owner, c.repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("Issues.ListByRepo(milestone %d): %w", milestone, err)
+		}
+
+		for _, i := range issues {
+			ret = append(ret, c.WrapIssue(i))
+		}
+
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+
+	return ret, nil
+}
+
+// MilestonePRs returns the merged pull requests of a milestone, sorted by
+// number.
+func (c *Client) MilestonePRs(ctx context.Context, milestone int) ([]*PR, error) {
+	opts := &github.IssueListByRepoOptions{
+		Milestone: strconv.Itoa(milestone),
+		State:     "closed",
+	}
+
+	var ret []*PR
+	for {
+		issues, res, err := c.Issues.ListByRepo(ctx, c.owner, c.repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("Issues.ListByRepo(milestone %d): %w", milestone, err)
+		}
+
+		for _, i := range issues {
+			if !i.IsPullRequest() {
+				continue
+			}
+
+			// Issues don't report whether a pull request has been merged.
+			pr, err := c.PR(ctx, i.GetNumber())
+			if err != nil {
+				return nil, err
+			}
+			if pr.GetMerged() {
+				ret = append(ret, pr)
+			}
+		}
+
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Number() < ret[j].Number()
+	})
+	return ret, nil
+}
+
+// CommitsBetween returns the commits reachable from head but not from base.
+// The comparison is paginated; if not all commits could be retrieved, an error
+// is returned rather than an incomplete list.
+func (c *Client) CommitsBetween(ctx context.Context, base, head string) ([]github.RepositoryCommit, error) {
+	var (
+		ret   []github.RepositoryCommit
+		total int
+	)
+	for page := 1; page != 0; {
+		// CompareCommits of go-github does not support pagination.
+		u := fmt.Sprintf("repos/%v/%v/compare/%v...%v?per_page=100&page=%d", c.owner, c.repo, url.PathEscape(base), url.PathEscape(head), page)
+		req, err := c.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var cmp github.CommitsComparison
+		res, err := c.Do(ctx, req, &cmp)
+		if err != nil {
+			return nil, fmt.Errorf("CompareCommits(%q, %q): %w", base, head, err)
+		}
+
+		ret = append(ret, cmp.Commits...)
+		total = cmp.GetTotalCommits()
+		page = res.NextPage
+	}
+
+	if len(ret) < total {
+		return nil, fmt.Errorf("CompareCommits(%q, %q): got %d of %d commits", base, head, len(ret), total)
+	}
+	return ret, nil
+}
+
+// PreviousRelease returns the latest published release other than the one
+// with the ID id. If there is no such release, os.ErrNotExist is returned.
+func (c *Client) PreviousRelease(ctx context.Context, id int64) (*github.RepositoryRelease, error) {
+	rels, _, err := c.Repositories.ListReleases(ctx, c.owner, c.repo, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Repositories.ListReleases(): %w", err)
+	}
+
+	for _, r := range rels {
+		if r.GetID() == id || r.GetDraft() {
+			continue
+		}
+		return r, nil
+	}
+
+	return nil, os.ErrNotExist
+}
+
+// ReleaseByTag returns the release, published or draft, for tag. Unlike
+// Repositories.GetReleaseByTag, this finds drafts, whose tag may not exist yet.
+// If there is no such release, os.ErrNotExist is returned.
+func (c *Client) ReleaseByTag(ctx context.Context, tag string) (*github.RepositoryRelease, error) {
+	opts := &github.ListOptions{}
+	for {
+		rels, res, err := c.Repositories.ListReleases(ctx, c.owner, c.repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("Repositories.ListReleases(): %w", err)
+		}
+
+		for _, r := range rels {
+			if r.GetTagName() == tag {
+				return r, nil
+			}
+		}
+
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+
+	return nil, os.ErrNotExist
+}
+
+// CreateDraftRelease creates a draft release for tag.
+func (c *Client) CreateDraftRelease(ctx context.Context, tag, name, body string) (*github.RepositoryRelease, error) {
+	r, _, err := c.Repositories.CreateRelease(ctx, c.owner, c.repo, &github.RepositoryRelease{
+		TagName: github.String(tag),
+		Name:    github.String(name),
+		Body:    github.String(body),
+		Draft:   github.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Repositories.CreateRelease(%q): %w", tag, err)
+	}
+
+	return r, nil
+}
+
+// SetReleaseBody replaces the description of the release with the ID id.
+func (c *Client) SetReleaseBody(ctx context.Context, id int64, body string) error {
+	_, _, err := c.Repositories.EditRelease(ctx, c.owner, c.repo, id, &github.RepositoryRelease{
+		Body: github.String(body),
+	})
+	if err != nil {
+		return fmt.Errorf("Repositories.EditRelease(%d): %w", id, err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/google/go-github/github"
@@ -83,3 +84,33 @@ func (s *Stage) Commit(ctx context.Context, message string) error {
 	s.entries = nil
 	return nil
 }
+
+// CommitFile creates a commit on top of parent that sets the content of the
+// file at path and returns the new commit's SHA. No reference is updated.
+func (c *Client) CommitFile(ctx context.Context, parent, path, content, message string) (string, error) {
+	baseCommit, _, err := c.Git.GetCommit(ctx, c.owner, c.repo, parent)
+	if err != nil {
+		return "", fmt.Errorf("Git.GetCommit(%q): %w", parent, err)
+	}
+
+	tree, _, err := c.Git.CreateTree(ctx, c.owner, c.repo, baseCommit.Tree.GetSHA(), []github.TreeEntry{{
+		Path:    github.String(path),
+		Mode:    github.String("100644"),
+		Type:    github.String("blob"),
+		Content: github.String(content),
+	}})
+	if err != nil {
+		return "", fmt.Errorf("Git.CreateTree(): %w", err)
+	}
+
+	commit, _, err := c.Git.CreateCommit(ctx, c.owner, c.repo, &github.Commit{
+		Message: github.String(message),
+		Tree:    tree,
+		Parents: []github.Commit{{SHA: baseCommit.SHA}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("Git.CreateCommit(): %w", err)
+	}
+
+	return commit.GetSHA(), nil
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// StickyMarker returns the hidden marker identifying the sticky comment of
+// action.
+func StickyMarker(action string) string {
+	return fmt.Sprintf("<!-- ghbot:%s -->", action)
+}
+
+// stickyComment returns the comment on the issue written by the bot that
+// contains the marker of action, or nil.
+func (i *Issue) stickyComment(ctx context.Context, action string) (*github.IssueComment, error) {
+	self, err := i.client.Login(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := i.Comments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	marker := StickyMarker(action)
+	for _, cmt := range comments {
+		if cmt.GetUser().GetLogin() == self && strings.Contains(cmt.GetBody(), marker) {
+			return cmt, nil
+		}
+	}
+	return nil, nil
+}
+
+// SetStickyComment makes sure the issue has exactly one comment by action
+// with body. The comment is created if necessary and edited if its body
+// differs. If body is empty, the comment is deleted.
+func (i *Issue) SetStickyComment(ctx context.Context, action, body string) error {
+	cmt, err := i.stickyComment(ctx, action)
+	if err != nil {
+		return err
+	}
+
+	c := i.client
+	switch {
+	case body == "" && cmt == nil:
+		return nil
+	case body == "":
+		if _, err := c.Issues.DeleteComment(ctx, c.owner, c.repo, cmt.GetID()); err != nil {
+			return fmt.Errorf("Issues.DeleteComment(#%d, %d): %w", i.Number(), cmt.GetID(), err)
+		}
+		return nil
+	}
+
+	body = StickyMarker(action) + "\n" + body
+	switch {
+	case cmt == nil:
+		return i.Comment(ctx, body)
+	case cmt.GetBody() == body:
+		return nil
+	default:
+		return i.EditComment(ctx, cmt.GetID(), body)
+	}
+}
+
+// DeleteStickyComment deletes the sticky comment of action, if any.
+func (i *Issue) DeleteStickyComment(ctx context.Context, action string) error {
+	return i.SetStickyComment(ctx, action, "")
+}
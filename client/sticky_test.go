@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+// fakeComments serves the endpoints used by sticky comments and records all
+// modifying requests.
+type fakeComments struct {
+	login    string
+	comments []*github.IssueComment
+	calls    []string
+}
+
+func (f *fakeComments) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/user":
+		json.NewEncoder(w).Encode(&github.User{Login: github.String(f.login)})
+		return
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/o/r/issues/1/comments":
+		json.NewEncoder(w).Encode(f.comments)
+		return
+	}
+
+	var cmt github.IssueComment
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&cmt)
+	}
+	f.calls = append(f.calls, fmt.Sprintf("%s %s %q", r.Method, r.URL.Path, cmt.GetBody()))
+
+	switch r.Method {
+	case http.MethodDelete:
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		json.NewEncoder(w).Encode(&cmt)
+	}
+}
+
+func comment(id int64, login, body string) *github.IssueComment {
+	return &github.IssueComment{
+		ID:   github.Int64(id),
+		User: &github.User{Login: github.String(login)},
+		Body: github.String(body),
+	}
+}
+
+func TestSetStickyComment(t *testing.T) {
+	marker := StickyMarker("test") + "\n"
+
+	cases := []struct {
+		name     string
+		comments []*github.IssueComment
+		body     string
+		want     []string
+	}{
+		{
+			name: "create",
+			body: "hello",
+			want: []string{`POST /repos/o/r/issues/1/comments "` + StickyMarker("test") + `\nhello"`},
+		},
+		{
+			name:     "unchanged",
+			comments: []*github.IssueComment{comment(10, "ghbot", marker+"hello")},
+			body:     "hello",
+		},
+		{
+			name:     "edit",
+			comments: []*github.IssueComment{comment(10, "ghbot", marker+"hello")},
+			body:     "bye",
+			want:     []string{`PATCH /repos/o/r/issues/comments/10 "` + StickyMarker("test") + `\nbye"`},
+		},
+		{
+			name: "other author",
+			comments: []*github.IssueComment{
+				comment(10, "octocat", "> "+marker+"hello"),
+				comment(11, "ghbot", StickyMarker("other")+"\nhello"),
+			},
+			body: "hello",
+			want: []string{`POST /repos/o/r/issues/1/comments "` + StickyMarker("test") + `\nhello"`},
+		},
+		{
+			name: "delete",
+			comments: []*github.IssueComment{
+				comment(10, "octocat", "thanks"),
+				comment(11, "ghbot", marker+"hello"),
+			},
+			want: []string{`DELETE /repos/o/r/issues/comments/11 ""`},
+		},
+		{
+			name:     "nothing to delete",
+			comments: []*github.IssueComment{comment(10, "octocat", marker+"hello")},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &fakeComments{login: "ghbot", comments: tc.comments}
+			srv := httptest.NewServer(f)
+			defer srv.Close()
+
+			gh := github.NewClient(srv.Client())
+			gh.BaseURL, _ = url.Parse(srv.URL + "/")
+			c := &Client{owner: "o", repo: "r", Client: gh}
+			issue := c.WrapIssue(&github.Issue{Number: github.Int(1)})
+
+			if err := issue.SetStickyComment(context.Background(), "test", tc.body); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(f.calls, tc.want) {
+				t.Errorf("SetStickyComment(%q) made the requests %q, want %q", tc.body, f.calls, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+// Package command implements commands users can give the bot in issue and
+// pull request comments.
+//
+// A command is a line of the form
+//
+//	/ghbot <command> [args...]
+//
+// Actions register commands with Register. When a comment containing commands
+// is created, the commenter's permission level on the repository is checked
+// and each command is run. The bot acknowledges commands with a reaction on
+// the comment and replies with the commands' output, if any.
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/github"
+	"github.com/mtraver/gaelog"
+	"github.com/octo/ghbot/client"
+	"github.com/octo/ghbot/event"
+)
+
+const prefix = "/ghbot"
+
+// Permission levels, as returned by client.Permission.
+const (
+	PermissionNone  = "none"
+	PermissionRead  = "read"
+	PermissionWrite = "write"
+	PermissionAdmin = "admin"
+)
+
+var permissionRank = map[string]int{
+	PermissionNone:  0,
+	PermissionRead:  1,
+	PermissionWrite: 2,
+	PermissionAdmin: 3,
+}
+
+// Request holds the information about a single command invocation.
+type Request struct {
+	Client *client.Client
+	Issue  *client.Issue
+	// PR is set if the comment was made on a pull request.
+	PR      *client.PR
+	Comment *github.IssueComment
+	// User is the login of the commenter.
+	User string
+	Args []string
+}
+
+// Command is a command that can be given to the bot.
+type Command struct {
+	Name string
+	// Usage describes the arguments, e.g. "<action>...".
+	Usage string
+	Help  string
+	// Permission is the minimum permission level required to run the
+	// command, e.g. PermissionWrite.
+	Permission string
+	// PullRequestOnly restricts the command to pull request comments.
+	PullRequestOnly bool
+	// Run runs the command. If it returns a non-empty string, it is posted
+	// as a reply.
+	Run func(ctx context.Context, req *Request) (string, error)
+}
+
+var (
+	mu       sync.Mutex
+	commands = map[string]Command{}
+)
+
+// Register registers a command. Registering a command with the same name
+// twice replaces the first registration.
+func Register(cmd Command) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	commands[cmd.Name] = cmd
+}
+
+func lookup(name string) (Command, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	cmd, ok := commands[name]
+	return cmd, ok
+}
+
+// Commands returns all registered commands, sorted by name.
+func Commands() []Command {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var ret []Command
+	for _, cmd := range commands {
+		ret = append(ret, cmd)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+// Invocation is a command found in a comment.
+type Invocation struct {
+	Name string
+	Args []string
+}
+
+// Parse returns all commands in body. Commands in code blocks and quotes, e.g.
+// when replying to a comment containing commands, are ignored.
+func Parse(body string) []Invocation {
+	var (
+		ret    []Invocation
+		fenced bool
+	)
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		if fenced || strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != prefix {
+			continue
+		}
+
+		ret = append(ret, Invocation{
+			Name: strings.ToLower(fields[1]),
+			Args: fields[2:],
+		})
+	}
+	return ret
+}
+
+func init() {
+	event.IssueCommentHandler("command", handler)
+}
+
+func handler(ctx context.Context, e *github.IssueCommentEvent) error {
+	if e.GetAction() != "created" || e.GetComment().GetUser().GetType() == "Bot" {
+		return nil
+	}
+
+	invs := Parse(e.GetComment().GetBody())
+	if len(invs) == 0 {
+		return nil
+	}
+
+	c, err := client.New(ctx, client.DefaultOwner, client.DefaultRepo)
+	if err != nil {
+		return err
+	}
+
+	// The bot authenticates as a regular user, so its own comments are
+	// only recognized by their author.
+	self, err := c.Login(ctx)
+	if err != nil {
+		return err
+	}
+	if e.GetComment().GetUser().GetLogin() == self {
+		return nil
+	}
+
+	req := &Request{
+		Client:  c,
+		Issue:   c.WrapIssue(e.GetIssue()),
+		Comment: e.GetComment(),
+		User:    e.GetComment().GetUser().GetLogin(),
+	}
+
+	if e.GetIssue().IsPullRequest() {
+		pr, err := c.PR(ctx, e.GetIssue().GetNumber())
+		if err != nil {
+			return err
+		}
+		req.PR = pr
+	}
+
+	perm, err := c.Permission(ctx, req.User)
+	if err != nil {
+		return err
+	}
+
+	var (
+		replies []string
+		failed  bool
+	)
+	for _, inv := range invs {
+		msg, err := run(ctx, req, perm, inv)
+		if err != nil {
+			gaelog.Warningf(ctx, "command %q by %s on %v: %v", inv.Name, req.User, req.Issue, err)
+			msg = fmt.Sprintf("`%s %s` failed: %v", prefix, inv.Name, err)
+			failed = true
+		}
+		if msg != "" {
+			replies = append(replies, msg)
+		}
+	}
+
+	reaction := "+1"
+	if failed {
+		reaction = "confused"
+	}
+	if err := c.React(ctx, req.Comment.GetID(), reaction); err != nil {
+		gaelog.Warningf(ctx, "command: %v", err)
+	}
+
+	if len(replies) == 0 {
+		return nil
+	}
+	return req.Issue.Comment(ctx, fmt.Sprintf("@%s %s", req.User, strings.Join(replies, "\n\n")))
+}
+
+func run(ctx context.Context, req *Request, perm string, inv Invocation) (string, error) {
+	cmd, ok := lookup(inv.Name)
+	if !ok {
+		return "", fmt.Errorf("unknown command; try `%s help`", prefix)
+	}
+
+	if permissionRank[perm] < permissionRank[cmd.Permission] {
+		return "", fmt.Errorf("requires %q permission, you have %q", cmd.Permission, perm)
+	}
+
+	if cmd.PullRequestOnly && req.PR == nil {
+		return "", fmt.Errorf("only available on pull requests")
+	}
+
+	r := *req
+	r.Args = inv.Args
+	return cmd.Run(ctx, &r)
+}
@@ -0,0 +1,36 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		body string
+		want []Invocation
+	}{
+		{"Looks good to me", nil},
+		{"/ghbot help", []Invocation{{Name: "help", Args: []string{}}}},
+		{"Thanks!\n\n/ghbot rerun changelog format\r\n", []Invocation{{Name: "rerun", Args: []string{"changelog", "format"}}}},
+		{"  /ghbot  Help  ", []Invocation{{Name: "help", Args: []string{}}}},
+		{"/ghbot", nil},
+		{"/ghbotx help", nil},
+		{"> /ghbot help", nil},
+		{">/ghbot help", nil},
+		{"```\n/ghbot help\n```\n/ghbot rerun", []Invocation{{Name: "rerun", Args: []string{}}}},
+		{"~~~ text\n/ghbot help\n~~~", nil},
+		{"```\n/ghbot help", nil},
+		{"/ghbot help\n/ghbot rerun", []Invocation{
+			{Name: "help", Args: []string{}},
+			{Name: "rerun", Args: []string{}},
+		}},
+	}
+
+	for _, tc := range cases {
+		got := Parse(tc.body)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Parse(%q) = %#v, want %#v", tc.body, got, tc.want)
+		}
+	}
+}
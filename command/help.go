@@ -0,0 +1,34 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	Register(Command{
+		Name:       "help",
+		Help:       "Lists the available commands.",
+		Permission: PermissionNone,
+		Run:        help,
+	})
+}
+
+func help(ctx context.Context, req *Request) (string, error) {
+	var b strings.Builder
+	fmt.Fprintln(&b, "Available commands:")
+	fmt.Fprintln(&b)
+	for _, cmd := range Commands() {
+		usage := prefix + " " + cmd.Name
+		if cmd.Usage != "" {
+			usage += " " + cmd.Usage
+		}
+		fmt.Fprintf(&b, "* `%s` — %s", usage, cmd.Help)
+		if cmd.Permission != PermissionNone {
+			fmt.Fprintf(&b, " (requires %q permission)", cmd.Permission)
+		}
+		fmt.Fprintln(&b)
+	}
+	return b.String(), nil
+}
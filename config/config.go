@@ -3,6 +3,7 @@ package config
 
 import (
 	"context"
+	"sync"
 
 	"cloud.google.com/go/datastore"
 )
@@ -10,16 +11,41 @@ import (
 type credentials struct {
 	SecretKey   string `datastore:",noindex"`
 	AccessToken string `datastore:",noindex"`
+	AdminToken  string `datastore:",noindex"`
 }
 
-var cachedCreds *credentials
+var (
+	cachedCreds *credentials
+
+	datastoreMu     sync.Mutex
+	datastoreClient *datastore.Client
+)
+
+// Datastore returns a client for the project's Datastore. The client is
+// created on first use and shared by all callers.
+func Datastore(ctx context.Context) (*datastore.Client, error) {
+	datastoreMu.Lock()
+	defer datastoreMu.Unlock()
+
+	if datastoreClient != nil {
+		return datastoreClient, nil
+	}
+
+	client, err := datastore.NewClient(ctx, datastore.DetectProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	datastoreClient = client
+	return client, nil
+}
 
 func loadCreds(ctx context.Context) error {
 	if cachedCreds != nil {
 		return nil
 	}
 
-	client, err := datastore.NewClient(ctx, datastore.DetectProjectID)
+	client, err := Datastore(ctx)
 	if err != nil {
 		return err
 	}
@@ -50,3 +76,14 @@ func AccessToken(ctx context.Context) (string, error) {
 
 	return cachedCreds.AccessToken, nil
 }
+
+// AdminToken returns the bearer token required to access administrative
+// endpoints. If no token is configured, an empty string is returned and
+// administrative endpoints are disabled.
+func AdminToken(ctx context.Context) (string, error) {
+	if err := loadCreds(ctx); err != nil {
+		return "", err
+	}
+
+	return cachedCreds.AdminToken, nil
+}
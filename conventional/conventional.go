@@ -0,0 +1,113 @@
+// Package conventional parses commit messages and pull request titles
+// following the Conventional Commits specification, e.g.
+//
+//	feat(cpu)!: Report steal time by default.
+//
+// In addition to the specification, the "[collectd 6]" tag is accepted either
+// before the type or at the start of the description.
+package conventional
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Collectd6Tag marks changes targeting collectd 6.
+const Collectd6Tag = "[collectd 6]"
+
+// Types are the allowed commit types.
+var Types = []string{
+	"build",
+	"chore",
+	"ci",
+	"docs",
+	"feat",
+	"fix",
+	"perf",
+	"refactor",
+	"style",
+	"test",
+}
+
+var (
+	headerRE   = regexp.MustCompile(`^([A-Za-z]+)(\(([^()]*)\))?(!)?:\s*(.*)$`)
+	breakingRE = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:`)
+)
+
+// Header is a parsed commit message header or pull request title.
+type Header struct {
+	Type        string
+	Scope       string
+	Breaking    bool
+	Collectd6   bool
+	Description string
+}
+
+func allowedType(t string) bool {
+	for _, a := range Types {
+		if t == a {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse parses the first line of s, which is a commit message or pull request
+// title.
+func Parse(s string) (Header, error) {
+	var h Header
+
+	line, _, _ := strings.Cut(s, "\n")
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, Collectd6Tag) {
+		h.Collectd6 = true
+		line = strings.TrimSpace(strings.TrimPrefix(line, Collectd6Tag))
+	}
+
+	m := headerRE.FindStringSubmatch(line)
+	if m == nil {
+		return h, fmt.Errorf(`%q does not match "<type>[(<scope>)][!]: <description>"`, line)
+	}
+
+	h.Type = m[1]
+	h.Scope = strings.TrimSpace(m[3])
+	h.Breaking = m[4] == "!"
+	h.Description = m[5]
+	if strings.HasPrefix(h.Description, Collectd6Tag) {
+		h.Collectd6 = true
+		h.Description = strings.TrimSpace(strings.TrimPrefix(h.Description, Collectd6Tag))
+	}
+
+	if !allowedType(h.Type) {
+		return h, fmt.Errorf("unknown type %q, use one of %s", h.Type, strings.Join(Types, ", "))
+	}
+	if m[2] != "" && h.Scope == "" {
+		return h, fmt.Errorf("empty scope, remove the parentheses or name a component")
+	}
+	if h.Description == "" {
+		return h, fmt.Errorf("missing description after %q", h.Type+":")
+	}
+
+	return h, nil
+}
+
+// ParseMessage parses a full commit message. In addition to Parse, it checks
+// that the body is separated from the header by an empty line and recognizes
+// "BREAKING CHANGE:" footers.
+func ParseMessage(msg string) (Header, error) {
+	h, err := Parse(msg)
+	if err != nil {
+		return h, err
+	}
+
+	lines := strings.Split(strings.TrimRight(msg, "\n"), "\n")
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		return h, fmt.Errorf("separate the header from the body with an empty line")
+	}
+
+	if breakingRE.MatchString(msg) {
+		h.Breaking = true
+	}
+	return h, nil
+}
@@ -0,0 +1,59 @@
+package conventional
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    Header
+		wantErr bool
+	}{
+		{in: "feat: Something new", want: Header{Type: "feat", Description: "Something new"}},
+		{in: "feat(cpu): New option", want: Header{Type: "feat", Scope: "cpu", Description: "New option"}},
+		{in: "fix(cpu)!: Changed default", want: Header{Type: "fix", Scope: "cpu", Breaking: true, Description: "Changed default"}},
+		{in: "[collectd 6] feat: v6 feature", want: Header{Type: "feat", Collectd6: true, Description: "v6 feature"}},
+		{in: "feat: [collectd 6] v6 feature", want: Header{Type: "feat", Collectd6: true, Description: "v6 feature"}},
+		{in: "fix: [collectd 6] feat: v6 feature", want: Header{Type: "fix", Collectd6: true, Description: "feat: v6 feature"}},
+		{in: "chore: Bump version\n\nDetails.", want: Header{Type: "chore", Description: "Bump version"}},
+		{in: "testing: unknown type", wantErr: true},
+		{in: "Feat: capitalized type", wantErr: true},
+		{in: "feat():", wantErr: true},
+		{in: "feat: ", wantErr: true},
+		{in: "Fixed the thing", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		got, err := Parse(tc.in)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("Parse(%q) = %v, want error %v", tc.in, err, tc.wantErr)
+			continue
+		}
+		if !tc.wantErr && got != tc.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseMessage(t *testing.T) {
+	cases := []struct {
+		msg          string
+		wantBreaking bool
+		wantErr      bool
+	}{
+		{"fix: Thing\n\nBody.", false, false},
+		{"fix: Thing\n\nBREAKING CHANGE: The option is gone.", true, false},
+		{"fix: Thing\nBody without empty line.", false, true},
+		{"fix: Thing\n", false, false},
+	}
+
+	for _, tc := range cases {
+		got, err := ParseMessage(tc.msg)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("ParseMessage(%q) = %v, want error %v", tc.msg, err, tc.wantErr)
+			continue
+		}
+		if got.Breaking != tc.wantBreaking {
+			t.Errorf("ParseMessage(%q).Breaking = %v, want %v", tc.msg, got.Breaking, tc.wantBreaking)
+		}
+	}
+}
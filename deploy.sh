@@ -9,4 +9,4 @@ declare -r VERSION="v$(date +%s)"
 
 export CLOUDSDK_CORE_DISABLE_PROMPTS=1
 
-gcloud app deploy --account="${ACCT}" --project="${PROJ}" --version="${VERSION}"
+gcloud app deploy --account="${ACCT}" --project="${PROJ}" --version="${VERSION}" app.yaml cron.yaml
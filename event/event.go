@@ -87,6 +87,8 @@ func Handle(ctx context.Context, event interface{}) error {
 		return handleTeam(ctx, event)
 	case *github.WatchEvent:
 		return handleWatch(ctx, event)
+	case *MergeGroupEvent:
+		return handleMergeGroup(ctx, event)
 	default:
 		log.Printf("unimplemented event type: %T", event)
 	}
@@ -2145,3 +2147,60 @@ func handleWatch(ctx context.Context, event *github.WatchEvent) error {
 
 	return lastErr
 }
+
+//
+// MergeGroup events
+//
+var mergeGroupHandlers = map[string]func(context.Context, *MergeGroupEvent) error{}
+
+// MergeGroupHandler registers a handler for MergeGroup events.
+func MergeGroupHandler(name string, hndl func(context.Context, *MergeGroupEvent) error) {
+	mergeGroupHandlers[name] = hndl
+}
+
+// handleMergeGroup calls all handlers for MergeGroup events. If a handler
+// returns an error, that error is returned immediately and no further handlers
+// are called.
+func handleMergeGroup(ctx context.Context, event *MergeGroupEvent) error {
+	ctx, span := trace.StartSpan(ctx, "Event MergeGroup")
+	span.AddAttributes(
+		trace.StringAttribute("/github/event", "MergeGroup"),
+	)
+	defer span.End()
+
+	wg := sync.WaitGroup{}
+	ch := make(chan error)
+
+	for name, hndl := range mergeGroupHandlers {
+		wg.Add(1)
+
+		go func(name string, hndl func(context.Context, *MergeGroupEvent) error) {
+			defer wg.Done()
+
+			ctx, span := trace.StartSpan(ctx, "Action "+name)
+			span.AddAttributes(
+				trace.StringAttribute("/github/bot/action", name),
+			)
+			defer span.End()
+
+			if err := hndl(ctx, event); err != nil {
+				ch <- fmt.Errorf("%q MergeGroup handler: %v", name, err)
+			}
+		}(name, hndl)
+	}
+
+	go func() {
+		wg.Wait()
+		close(ch)
+	}()
+
+	var lastErr error
+	for err := range ch {
+		if lastErr != nil {
+			log.Print(lastErr)
+		}
+		lastErr = err
+	}
+
+	return lastErr
+}
@@ -49,6 +49,19 @@ my @eventTypes = qw(
     Watch
 );
 
+# Event types not (yet) supported by go-github. These types are defined in
+# this package, see types.go.
+my %localEventTypes = map { $_ => 1 } qw(
+    MergeGroup
+);
+push @eventTypes, sort keys %localEventTypes;
+
+sub goType {
+	my $type = shift;
+	return "${type}Event" if $localEventTypes{$type};
+	return "github.${type}Event";
+}
+
 print <<EOF;
 // This file was generated by $0
 
@@ -70,8 +83,9 @@ func Handle(ctx context.Context, event interface{}) error {
 EOF
 for (@eventTypes) {
 	my $type = $_;
+	my $goType = goType($type);
 	print <<EOF;
-	case *github.${type}Event:
+	case *${goType}:
 		return handle${type}(ctx, event)
 EOF
 }
@@ -87,23 +101,24 @@ EOF
 for (@eventTypes) {
 	my $type = $_;
 	my $global_var = lcfirst($type) . 'Handlers';
+	my $goType = goType($type);
 
 	print <<EOF;
 
 //
 // $type events
 //
-var $global_var = map[string]func(context.Context, *github.${type}Event) error{}
+var $global_var = map[string]func(context.Context, *${goType}) error{}
 
 // ${type}Handler registers a handler for ${type} events.
-func ${type}Handler(name string, hndl func(context.Context, *github.${type}Event) error) {
+func ${type}Handler(name string, hndl func(context.Context, *${goType}) error) {
 	$global_var\[name\] = hndl
 }
 
 // handle${type} calls all handlers for ${type} events. If a handler
 // returns an error, that error is returned immediately and no further handlers
 // are called.
-func handle${type}(ctx context.Context, event *github.${type}Event) error {
+func handle${type}(ctx context.Context, event *${goType}) error {
 	ctx, span := trace.StartSpan(ctx, "Event ${type}")
 	span.AddAttributes(
 		trace.StringAttribute("/github/event", "${type}"),
@@ -116,7 +131,7 @@ func handle${type}(ctx context.Context, event *github.${type}Event) error {
 	for name, hndl := range $global_var {
 		wg.Add(1)
 
-		go func(name string, hndl func(context.Context, *github.${type}Event) error) {
+		go func(name string, hndl func(context.Context, *${goType}) error) {
 			defer wg.Done()
 
 			ctx, span := trace.StartSpan(ctx, "Action "+name)
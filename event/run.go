@@ -0,0 +1,57 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/go-github/github"
+	"go.opencensus.io/trace"
+	"go.uber.org/multierr"
+)
+
+// PullRequestHandlerNames returns the names of all registered PullRequest
+// handlers, sorted.
+func PullRequestHandlerNames() []string {
+	var names []string
+	for name := range pullRequestHandlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SynchronizeEvent returns a PullRequest event as if the head branch of pr had
+// just been pushed to.
+func SynchronizeEvent(pr *github.PullRequest) *github.PullRequestEvent {
+	return &github.PullRequestEvent{
+		Action:      github.String("synchronize"),
+		Number:      github.Int(pr.GetNumber()),
+		PullRequest: pr,
+		Repo:        pr.GetBase().GetRepo(),
+	}
+}
+
+// RunPullRequestHandlers calls the PullRequest handlers called names with the
+// synthesized event e. This allows re-running actions outside of the normal
+// webhook flow. Unlike Handle, handlers are called sequentially and all errors
+// are returned.
+func RunPullRequestHandlers(ctx context.Context, e *github.PullRequestEvent, names ...string) error {
+	ctx, span := trace.StartSpan(ctx, "Rerun PullRequest")
+	defer span.End()
+
+	var errs error
+	for _, name := range names {
+		hndl, ok := pullRequestHandlers[name]
+		if !ok {
+			errs = multierr.Append(errs, fmt.Errorf("no PullRequest handler called %q", name))
+			continue
+		}
+
+		if err := hndl(ctx, e); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("%q PullRequest handler: %v", name, err))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,93 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-github/github"
+)
+
+// MergeGroup is a group of pull requests in Github's merge queue that is
+// tested together before being merged into the base branch.
+type MergeGroup struct {
+	HeadSHA *string `json:"head_sha,omitempty"`
+	HeadRef *string `json:"head_ref,omitempty"`
+	BaseSHA *string `json:"base_sha,omitempty"`
+	BaseRef *string `json:"base_ref,omitempty"`
+}
+
+func (m *MergeGroup) GetHeadSHA() string {
+	if m == nil || m.HeadSHA == nil {
+		return ""
+	}
+	return *m.HeadSHA
+}
+
+func (m *MergeGroup) GetHeadRef() string {
+	if m == nil || m.HeadRef == nil {
+		return ""
+	}
+	return *m.HeadRef
+}
+
+func (m *MergeGroup) GetBaseRef() string {
+	if m == nil || m.BaseRef == nil {
+		return ""
+	}
+	return *m.BaseRef
+}
+
+// MergeGroupEvent is triggered when a merge group is created in, or removed
+// from, Github's merge queue. The Webhook event name is "merge_group".
+type MergeGroupEvent struct {
+	// The action performed. Can be "checks_requested" or "destroyed".
+	Action     *string     `json:"action,omitempty"`
+	MergeGroup *MergeGroup `json:"merge_group,omitempty"`
+	// Reason is set for "destroyed" events, e.g. "merged" or "invalidated".
+	Reason *string `json:"reason,omitempty"`
+
+	Repo   *github.Repository `json:"repository,omitempty"`
+	Sender *github.User       `json:"sender,omitempty"`
+}
+
+func (e *MergeGroupEvent) GetAction() string {
+	if e == nil || e.Action == nil {
+		return ""
+	}
+	return *e.Action
+}
+
+func (e *MergeGroupEvent) GetMergeGroup() *MergeGroup {
+	if e == nil {
+		return nil
+	}
+	return e.MergeGroup
+}
+
+func (e *MergeGroupEvent) GetReason() string {
+	if e == nil || e.Reason == nil {
+		return ""
+	}
+	return *e.Reason
+}
+
+// localEventTypes maps webhook types to constructors of the types defined in
+// this package.
+var localEventTypes = map[string]func() interface{}{
+	"merge_group": func() interface{} { return &MergeGroupEvent{} },
+}
+
+// ParseWebHook parses the event payload, like github.ParseWebHook, but also
+// supports the event types defined in this package.
+func ParseWebHook(messageType string, payload []byte) (interface{}, error) {
+	newEvent, ok := localEventTypes[messageType]
+	if !ok {
+		return github.ParseWebHook(messageType, payload)
+	}
+
+	event := newEvent()
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, fmt.Errorf("parsing %q event: %w", messageType, err)
+	}
+	return event, nil
+}
@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"contrib.go.opencensus.io/exporter/stackdriver"
 	"contrib.go.opencensus.io/exporter/stackdriver/propagation"
@@ -13,15 +16,26 @@ import (
 	"github.com/mtraver/gaelog"
 	"github.com/octo/ghbot/config"
 	"github.com/octo/ghbot/event"
+	"github.com/octo/ghbot/mergequeue"
+	"github.com/octo/ghbot/scheduler"
 	"go.opencensus.io/plugin/ochttp"
 	"go.opencensus.io/trace"
 
 	_ "github.com/octo/ghbot/actions/automerge"
+	_ "github.com/octo/ghbot/actions/backport"
 	_ "github.com/octo/ghbot/actions/changelog"
+	_ "github.com/octo/ghbot/actions/commitlint"
 	_ "github.com/octo/ghbot/actions/format"
 	_ "github.com/octo/ghbot/actions/labels"
+	_ "github.com/octo/ghbot/actions/labelsync"
 	_ "github.com/octo/ghbot/actions/milestone"
 	_ "github.com/octo/ghbot/actions/newplugin"
+	_ "github.com/octo/ghbot/actions/reconcile"
+	_ "github.com/octo/ghbot/actions/rerun"
+	_ "github.com/octo/ghbot/actions/size"
+	_ "github.com/octo/ghbot/actions/stale"
+	_ "github.com/octo/ghbot/actions/welcome"
+	_ "github.com/octo/ghbot/command"
 )
 
 func main() {
@@ -48,6 +62,24 @@ func main() {
 		Handler:          http.HandlerFunc(handler),
 		IsPublicEndpoint: true,
 	})
+	http.Handle("/admin/mergequeue", &ochttp.Handler{
+		Propagation: &propagation.HTTPFormat{},
+		Handler:     adminHandler(http.HandlerFunc(mergequeue.Handler)),
+	})
+	http.Handle("/admin/cron", &ochttp.Handler{
+		Propagation: &propagation.HTTPFormat{},
+		Handler:     cronHandler(http.HandlerFunc(scheduler.Handler)),
+	})
+
+	// Outside of App Engine there is no cron service requesting /admin/cron.
+	if s := os.Getenv("SCHEDULER_INTERVAL"); s != "" {
+		interval, err := time.ParseDuration(s)
+		if err != nil {
+			log.Fatalf("SCHEDULER_INTERVAL: %v", err)
+		}
+		go scheduler.Run(context.Background(), interval)
+	}
+
 	if err := http.ListenAndServe(":"+port, nil); err != nil {
 		log.Fatalln("http.ListenAndServe:", err)
 	}
@@ -86,7 +118,7 @@ func contextHandler(ctx context.Context, w http.ResponseWriter, r *http.Request)
 		return processPing(ctx, w)
 	}
 
-	e, err := github.ParseWebHook(whType, payload)
+	e, err := event.ParseWebHook(whType, payload)
 	if err != nil {
 		gaelog.Errorf(ctx, "ParseWebHook: %v", err)
 		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
@@ -101,6 +133,45 @@ func contextHandler(ctx context.Context, w http.ResponseWriter, r *http.Request)
 	return nil
 }
 
+// adminHandler only passes requests on to h if they carry the admin token as
+// bearer token in the "Authorization" header.
+func adminHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		token, err := config.AdminToken(ctx)
+		if err != nil {
+			gaelog.Errorf(ctx, "AdminToken: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// cronHandler passes requests from App Engine cron on to h. Other requests are
+// handled by adminHandler. App Engine strips the "X-Appengine-Cron" header from
+// external requests, so it can be trusted -- but only when running on App
+// Engine. Elsewhere anyone can set it.
+func cronHandler(h http.Handler) http.Handler {
+	admin := adminHandler(h)
+	onAppEngine := os.Getenv("GAE_ENV") != ""
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if onAppEngine && r.Header.Get("X-Appengine-Cron") == "true" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		admin.ServeHTTP(w, r)
+	})
+}
+
 func processPing(ctx context.Context, w http.ResponseWriter) error {
 	fmt.Fprintln(w, "pong")
 	return nil
@@ -0,0 +1,26 @@
+package glob
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"src/*.c", "src/cpu.c", true},
+		{"src/*.c", "src/utils/common.c", false},
+		{"src/**/*.c", "src/cpu.c", true},
+		{"src/**/*.c", "src/utils/common/common.c", true},
+		{".github/**", ".github/workflows/build.yml", true},
+		{"**/*.pb-c.[ch]", "src/daemon/types.pb-c.h", true},
+		{"?akefile.am", "Makefile.am", true},
+		{"Makefile.am", "src/Makefile.am", false},
+		{"src", "src/cpu.c", false},
+	}
+
+	for _, tc := range cases {
+		if got := Match(tc.pattern, tc.name); got != tc.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tc.pattern, tc.name, got, tc.want)
+		}
+	}
+}
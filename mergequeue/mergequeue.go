@@ -0,0 +1,194 @@
+// Package mergequeue persists per-branch queues of pull requests waiting to be
+// merged.
+//
+// Queues are stored in Datastore so that concurrent webhook invocations agree
+// on which pull request is merged next. Only the pull request at the head of a
+// queue is brought up to date with its base branch and merged; all others wait
+// for their turn.
+package mergequeue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/octo/ghbot/config"
+)
+
+const kind = "MergeQueue"
+
+// Queue is the merge queue of a single branch.
+type Queue struct {
+	// Repo is the repository as "owner/repo".
+	Repo   string
+	Branch string
+	// PRs holds the numbers of queued pull requests, head first.
+	PRs []int
+	// HeadSince is the time the current head of the queue got there.
+	HeadSince time.Time
+	Updated   time.Time
+}
+
+func (q *Queue) head() int {
+	if len(q.PRs) == 0 {
+		return 0
+	}
+	return q.PRs[0]
+}
+
+// setHeadSince updates HeadSince after the queue has been modified. oldHead is
+// the head before the modification.
+func (q *Queue) setHeadSince(oldHead int, now time.Time) {
+	switch {
+	case len(q.PRs) == 0:
+		q.HeadSince = time.Time{}
+	case q.head() != oldHead || q.HeadSince.IsZero():
+		q.HeadSince = now
+	}
+}
+
+// Position returns the zero based position of number in the queue, or -1 if
+// it is not queued.
+func (q *Queue) Position(number int) int {
+	for i, n := range q.PRs {
+		if n == number {
+			return i
+		}
+	}
+	return -1
+}
+
+// remove removes number from the queue and returns true if it was queued. If
+// number was the head of the queue, next is the new head, if any.
+func (q *Queue) remove(number int) (next int, ok bool) {
+	i := q.Position(number)
+	if i == -1 {
+		return 0, false
+	}
+
+	q.PRs = append(q.PRs[:i], q.PRs[i+1:]...)
+	if i == 0 {
+		next = q.head()
+	}
+	return next, true
+}
+
+func key(repo, branch string) *datastore.Key {
+	return datastore.NameKey(kind, repo+":"+branch, nil)
+}
+
+// update loads the queue of repo and branch, calls f, and stores the queue if
+// f returns true. All of this happens in a transaction.
+func update(ctx context.Context, repo, branch string, f func(q *Queue) bool) (*Queue, error) {
+	client, err := config.Datastore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var q Queue
+	_, err = client.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		q = Queue{}
+		k := key(repo, branch)
+		if err := tx.Get(k, &q); err != nil && !errors.Is(err, datastore.ErrNoSuchEntity) {
+			return err
+		}
+		q.Repo = repo
+		q.Branch = branch
+
+		oldHead := q.head()
+		if !f(&q) {
+			return nil
+		}
+
+		q.Updated = time.Now()
+		q.setHeadSince(oldHead, q.Updated)
+		_, err := tx.Put(k, &q)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mergequeue %s:%s: %w", repo, branch, err)
+	}
+
+	return &q, nil
+}
+
+// Enqueue appends the pull request number to the queue of repo and branch,
+// unless it is already queued. It returns the updated queue.
+func Enqueue(ctx context.Context, repo, branch string, number int) (*Queue, error) {
+	return update(ctx, repo, branch, func(q *Queue) bool {
+		if q.Position(number) != -1 {
+			return false
+		}
+		q.PRs = append(q.PRs, number)
+		return true
+	})
+}
+
+// Remove removes the pull request number from the queue of repo and branch. If
+// number was the head of the queue, it returns the pull request that is the
+// head now. Otherwise, or if the queue is empty now, it returns 0.
+func Remove(ctx context.Context, repo, branch string, number int) (int, error) {
+	var next int
+	_, err := update(ctx, repo, branch, func(q *Queue) bool {
+		var ok bool
+		next, ok = q.remove(number)
+		return ok
+	})
+	if err != nil {
+		return 0, err
+	}
+	return next, nil
+}
+
+// Get returns the queue of repo and branch.
+func Get(ctx context.Context, repo, branch string) (*Queue, error) {
+	client, err := config.Datastore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q := Queue{
+		Repo:   repo,
+		Branch: branch,
+	}
+	if err := client.Get(ctx, key(repo, branch), &q); err != nil && !errors.Is(err, datastore.ErrNoSuchEntity) {
+		return nil, fmt.Errorf("mergequeue %s:%s: %w", repo, branch, err)
+	}
+
+	return &q, nil
+}
+
+// All returns all merge queues.
+func All(ctx context.Context) ([]*Queue, error) {
+	client, err := config.Datastore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var queues []*Queue
+	if _, err := client.GetAll(ctx, datastore.NewQuery(kind), &queues); err != nil {
+		return nil, fmt.Errorf("mergequeue: %w", err)
+	}
+
+	return queues, nil
+}
+
+// Handler serves all merge queues as JSON.
+func Handler(w http.ResponseWriter, r *http.Request) {
+	queues, err := All(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(queues); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,65 @@
+package mergequeue
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestQueue(t *testing.T) {
+	q := &Queue{PRs: []int{3, 1, 4}}
+
+	if got, want := q.Position(1), 1; got != want {
+		t.Errorf("Position(1) = %d, want %d", got, want)
+	}
+	if got, want := q.Position(5), -1; got != want {
+		t.Errorf("Position(5) = %d, want %d", got, want)
+	}
+
+	cases := []struct {
+		number   int
+		wantNext int
+		wantOK   bool
+		wantPRs  []int
+	}{
+		{5, 0, false, []int{3, 1, 4}},
+		{1, 0, true, []int{3, 4}},
+		{3, 4, true, []int{4}},
+		{4, 0, true, []int{}},
+	}
+
+	for _, tc := range cases {
+		next, ok := q.remove(tc.number)
+		if next != tc.wantNext || ok != tc.wantOK {
+			t.Errorf("remove(%d) = (%d, %v), want (%d, %v)", tc.number, next, ok, tc.wantNext, tc.wantOK)
+		}
+		if !reflect.DeepEqual(q.PRs, tc.wantPRs) {
+			t.Errorf("after remove(%d): PRs = %v, want %v", tc.number, q.PRs, tc.wantPRs)
+		}
+	}
+}
+
+func TestSetHeadSince(t *testing.T) {
+	t0 := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Hour)
+
+	cases := []struct {
+		name    string
+		q       Queue
+		oldHead int
+		want    time.Time
+	}{
+		{"new head", Queue{PRs: []int{1}}, 0, t1},
+		{"same head", Queue{PRs: []int{1, 2}, HeadSince: t0}, 1, t0},
+		{"head removed", Queue{PRs: []int{2}, HeadSince: t0}, 1, t1},
+		{"empty", Queue{HeadSince: t0}, 1, time.Time{}},
+		{"unknown", Queue{PRs: []int{1}}, 1, t1},
+	}
+
+	for _, tc := range cases {
+		tc.q.setHeadSince(tc.oldHead, t1)
+		if !tc.q.HeadSince.Equal(tc.want) {
+			t.Errorf("%s: HeadSince = %v, want %v", tc.name, tc.q.HeadSince, tc.want)
+		}
+	}
+}
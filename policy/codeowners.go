@@ -0,0 +1,96 @@
+package policy
+
+import (
+	"strings"
+
+	"github.com/octo/ghbot/glob"
+)
+
+type codeOwnersRule struct {
+	pattern string
+	owners  []string
+}
+
+// CodeOwners maps file paths to their owners, as configured in a CODEOWNERS
+// file.
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+// ParseCodeOwners parses the content of a CODEOWNERS file. Owners are
+// returned as they appear in the file, e.g. "@octo" or "@collectd/core".
+// Owners given as email addresses are ignored.
+func ParseCodeOwners(content string) *CodeOwners {
+	var co CodeOwners
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		rule := codeOwnersRule{pattern: fields[0]}
+		for _, o := range fields[1:] {
+			if strings.HasPrefix(o, "@") {
+				rule.owners = append(rule.owners, o)
+			}
+		}
+		co.rules = append(co.rules, rule)
+	}
+
+	return &co
+}
+
+// Owners returns the owners of file. The last matching rule takes precedence.
+func (co *CodeOwners) Owners(file string) []string {
+	for i := len(co.rules) - 1; i >= 0; i-- {
+		if matchCodeOwners(co.rules[i].pattern, file) {
+			return co.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// matchCodeOwners implements the subset of the gitignore pattern syntax that
+// is commonly used in CODEOWNERS files, by translating pattern for glob.Match.
+func matchCodeOwners(pattern, file string) bool {
+	if pattern == "*" {
+		return true
+	}
+
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+
+	if !anchored {
+		// Patterns without a slash match at any depth.
+		pattern = "**/" + pattern
+	}
+
+	return glob.Match(pattern, file)
+}
+
+// Teams returns all teams, as "org/team-slug", that own files.
+func (co *CodeOwners) Teams() []string {
+	var (
+		seen = map[string]bool{}
+		ret  []string
+	)
+	for _, r := range co.rules {
+		for _, o := range r.owners {
+			team := strings.TrimPrefix(o, "@")
+			if !strings.Contains(team, "/") || seen[team] {
+				continue
+			}
+			seen[team] = true
+			ret = append(ret, team)
+		}
+	}
+	return ret
+}
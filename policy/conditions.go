@@ -0,0 +1,321 @@
+package policy
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MinApprovals requires at least Count approving reviews. If MaxAge is not
+// zero, approvals submitted more than MaxAge before the last push are not
+// counted.
+type MinApprovals struct {
+	Count  int
+	MaxAge time.Duration
+}
+
+func (c MinApprovals) Evaluate(in *Input) Result {
+	name := fmt.Sprintf("at least %d approval(s)", c.Count)
+
+	var (
+		approvers = map[string]bool{}
+		stale     []string
+	)
+	for _, r := range in.Reviews {
+		if r.State != "APPROVED" {
+			continue
+		}
+		if c.MaxAge != 0 && r.SubmittedAt.Before(in.LastPush.Add(-c.MaxAge)) {
+			stale = append(stale, "@"+r.Login)
+			continue
+		}
+		approvers[r.Login] = true
+	}
+
+	if len(approvers) >= c.Count {
+		return Result{Condition: name, OK: true}
+	}
+
+	reason := fmt.Sprintf("%d approval(s)", len(approvers))
+	if len(stale) != 0 {
+		reason += fmt.Sprintf(", approval by %s predates the last push", strings.Join(stale, ", "))
+	}
+	return Result{Condition: name, Reason: reason}
+}
+
+// NoChangesRequested requires that no reviewer requested changes.
+type NoChangesRequested struct{}
+
+func (NoChangesRequested) Evaluate(in *Input) Result {
+	const name = "no changes requested"
+
+	for _, r := range in.Reviews {
+		if r.State == "CHANGES_REQUESTED" {
+			return Result{Condition: name, Reason: fmt.Sprintf("@%s requested changes", r.Login)}
+		}
+	}
+
+	return Result{Condition: name, OK: true}
+}
+
+// NoReviewComments requires that there are no unresolved review comments.
+type NoReviewComments struct{}
+
+func (NoReviewComments) Evaluate(in *Input) Result {
+	const name = "no unresolved review comments"
+
+	if len(in.Comments) == 0 {
+		return Result{Condition: name, OK: true}
+	}
+
+	var reasons []string
+	for _, c := range in.Comments {
+		reasons = append(reasons, fmt.Sprintf("review comment from @%s at %s unresolved", c.Login, c.URL))
+	}
+	return Result{Condition: name, Reason: strings.Join(reasons, "; ")}
+}
+
+// ApprovalFromTeam requires an approval by a member of Team, which is given
+// as "org/team-slug".
+type ApprovalFromTeam struct {
+	Team string
+}
+
+func (c ApprovalFromTeam) Evaluate(in *Input) Result {
+	name := fmt.Sprintf("approval from @%s", c.Team)
+
+	members := map[string]bool{}
+	for _, login := range in.TeamMembers[c.Team] {
+		members[login] = true
+	}
+
+	for _, r := range in.Reviews {
+		if r.State == "APPROVED" && members[r.Login] {
+			return Result{Condition: name, OK: true, Reason: "approved by @" + r.Login}
+		}
+	}
+
+	return Result{Condition: name, Reason: "no approval by a team member"}
+}
+
+// ApprovalFromCodeOwners requires that each changed file that has code owners
+// has been approved by at least one of them.
+type ApprovalFromCodeOwners struct{}
+
+func (ApprovalFromCodeOwners) Evaluate(in *Input) Result {
+	const name = "approval from code owners"
+
+	if in.CodeOwners == nil {
+		return Result{Condition: name, OK: true, Reason: "no CODEOWNERS file"}
+	}
+
+	approved := map[string]bool{}
+	for _, r := range in.Reviews {
+		if r.State == "APPROVED" {
+			approved["@"+r.Login] = true
+		}
+	}
+	for team, members := range in.TeamMembers {
+		for _, login := range members {
+			if approved["@"+login] {
+				approved["@"+team] = true
+			}
+		}
+	}
+
+	missing := map[string]bool{}
+	for _, f := range in.Files {
+		owners := in.CodeOwners.Owners(f)
+		if len(owners) == 0 {
+			continue
+		}
+
+		ok := false
+		for _, o := range owners {
+			if approved[o] {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			missing[strings.Join(owners, " or ")] = true
+		}
+	}
+
+	if len(missing) == 0 {
+		return Result{Condition: name, OK: true}
+	}
+
+	var reasons []string
+	for m := range missing {
+		reasons = append(reasons, "needs approval from "+m)
+	}
+	sort.Strings(reasons)
+	return Result{Condition: name, Reason: strings.Join(reasons, "; ")}
+}
+
+// RequiredCheck requires that all statuses and check runs whose name matches
+// Pattern succeeded, and that there is at least one. Pattern uses the syntax
+// of path.Match.
+type RequiredCheck struct {
+	Pattern string
+}
+
+func (c RequiredCheck) Evaluate(in *Input) Result {
+	name := fmt.Sprintf("check %s", c.Pattern)
+
+	var (
+		found   bool
+		failed  []string
+		pending = true
+	)
+	for _, m := range []map[string]string{in.Statuses, in.Checks} {
+		for n, state := range m {
+			if ok, _ := path.Match(c.Pattern, n); !ok {
+				continue
+			}
+			found = true
+			switch state {
+			case "success":
+				continue
+			case "", "pending":
+				// Check runs that have not completed have no conclusion.
+				failed = append(failed, fmt.Sprintf("check %s is pending", n))
+			default:
+				failed = append(failed, fmt.Sprintf("check %s is %q", n, state))
+				pending = false
+			}
+		}
+	}
+
+	if !found {
+		return Result{Condition: name, Reason: fmt.Sprintf("check %s missing", c.Pattern), Pending: true}
+	}
+	if len(failed) != 0 {
+		sort.Strings(failed)
+		return Result{Condition: name, Reason: strings.Join(failed, "; "), Pending: pending}
+	}
+	return Result{Condition: name, OK: true}
+}
+
+// CombinedState combines the states of statuses like Github does: "failure" if
+// any status failed or errored, "pending" if any is pending, and "success"
+// otherwise, including when there are no statuses.
+func CombinedState(statuses map[string]string) string {
+	ret := "success"
+	for _, state := range statuses {
+		switch state {
+		case "success":
+		case "failure", "error":
+			return "failure"
+		default:
+			ret = "pending"
+		}
+	}
+	return ret
+}
+
+// CombinedStatus requires the combined state of all statuses to be "success".
+// Statuses matching any of Ignore, using the syntax of path.Match, are not
+// taken into account.
+type CombinedStatus struct {
+	Ignore []string
+}
+
+func (c CombinedStatus) Evaluate(in *Input) Result {
+	const name = "overall status is success"
+
+	state := in.CombinedState
+	if len(c.Ignore) != 0 {
+		statuses := map[string]string{}
+		for n, s := range in.Statuses {
+			if !matchAny(c.Ignore, n) {
+				statuses[n] = s
+			}
+		}
+		state = CombinedState(statuses)
+	}
+
+	if state != "success" {
+		return Result{
+			Condition: name,
+			Reason:    fmt.Sprintf("overall status is %q", state),
+			Pending:   state == "pending",
+		}
+	}
+	return Result{Condition: name, OK: true}
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ForbiddenLabels requires that none of Labels is set.
+type ForbiddenLabels struct {
+	Labels []string
+}
+
+func (c ForbiddenLabels) Evaluate(in *Input) Result {
+	name := fmt.Sprintf("none of the labels %q", c.Labels)
+
+	for _, got := range in.Labels {
+		for _, l := range c.Labels {
+			if got == l {
+				return Result{Condition: name, Reason: fmt.Sprintf("has label %q", l)}
+			}
+		}
+	}
+	return Result{Condition: name, OK: true}
+}
+
+// AllowedAuthors requires the pull request to be authored by one of Logins.
+type AllowedAuthors struct {
+	Logins []string
+}
+
+func (c AllowedAuthors) Evaluate(in *Input) Result {
+	const name = "author is allowed"
+
+	for _, l := range c.Logins {
+		if strings.EqualFold(l, in.Author) {
+			return Result{Condition: name, OK: true}
+		}
+	}
+	return Result{Condition: name, Reason: fmt.Sprintf("@%s is not allowed to use automerge", in.Author)}
+}
+
+// BaseBranches requires the pull request to target a branch matching one of
+// Patterns. Patterns use the syntax of path.Match.
+type BaseBranches struct {
+	Patterns []string
+}
+
+func (c BaseBranches) Evaluate(in *Input) Result {
+	const name = "base branch is allowed"
+
+	for _, p := range c.Patterns {
+		if ok, _ := path.Match(p, in.Base); ok {
+			return Result{Condition: name, OK: true}
+		}
+	}
+	return Result{Condition: name, Reason: fmt.Sprintf("base branch %q is not allowed", in.Base)}
+}
+
+// Mergeable requires the pull request to be mergeable without conflicts.
+type Mergeable struct{}
+
+func (Mergeable) Evaluate(in *Input) Result {
+	const name = "no merge conflicts"
+
+	if !in.Mergeable {
+		return Result{Condition: name, Reason: "has merge conflicts"}
+	}
+	return Result{Condition: name, OK: true}
+}
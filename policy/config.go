@@ -0,0 +1,113 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Config is the declarative form of a policy. Zero values disable the
+// corresponding condition.
+type Config struct {
+	MinApprovals int `json:"min_approvals"`
+	// ApprovalMaxAge is the maximum age of an approval relative to the
+	// last push, e.g. "72h".
+	ApprovalMaxAge     Duration `json:"approval_max_age"`
+	NoChangesRequested bool     `json:"no_changes_requested"`
+	NoReviewComments   bool     `json:"no_review_comments"`
+	// DismissStaleReviews ignores reviews of commits other than the head
+	// of the pull request, similar to Github's "Dismiss stale pull request
+	// approvals when new commits are pushed" setting.
+	DismissStaleReviews bool `json:"dismiss_stale_reviews"`
+	// ApprovalFromTeams lists teams, as "org/team-slug", from each of which
+	// an approval is required.
+	ApprovalFromTeams      []string `json:"approval_from_teams"`
+	ApprovalFromCodeOwners bool     `json:"approval_from_code_owners"`
+	// RequiredChecks lists name patterns of statuses and check runs that
+	// must succeed.
+	RequiredChecks []string `json:"required_checks"`
+	CombinedStatus bool     `json:"combined_status"`
+	// CombinedStatusIgnore lists name patterns of statuses that don't count
+	// towards the combined status, e.g. advisory checks.
+	CombinedStatusIgnore []string `json:"combined_status_ignore"`
+	ForbiddenLabels      []string `json:"forbidden_labels"`
+	AllowedAuthors       []string `json:"allowed_authors"`
+	BaseBranches         []string `json:"base_branches"`
+	Mergeable            bool     `json:"mergeable"`
+}
+
+// Parse parses a JSON encoded Config.
+func Parse(data []byte) (Config, error) {
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("policy.Parse: %w", err)
+	}
+	return cfg, nil
+}
+
+// Policy returns the conditions described by the config.
+func (cfg Config) Policy() Policy {
+	var p Policy
+
+	if len(cfg.AllowedAuthors) != 0 {
+		p = append(p, AllowedAuthors{Logins: cfg.AllowedAuthors})
+	}
+	if len(cfg.BaseBranches) != 0 {
+		p = append(p, BaseBranches{Patterns: cfg.BaseBranches})
+	}
+	if len(cfg.ForbiddenLabels) != 0 {
+		p = append(p, ForbiddenLabels{Labels: cfg.ForbiddenLabels})
+	}
+	if cfg.MinApprovals != 0 {
+		p = append(p, MinApprovals{
+			Count:  cfg.MinApprovals,
+			MaxAge: time.Duration(cfg.ApprovalMaxAge),
+		})
+	}
+	for _, team := range cfg.ApprovalFromTeams {
+		p = append(p, ApprovalFromTeam{Team: team})
+	}
+	if cfg.ApprovalFromCodeOwners {
+		p = append(p, ApprovalFromCodeOwners{})
+	}
+	if cfg.NoChangesRequested {
+		p = append(p, NoChangesRequested{})
+	}
+	if cfg.NoReviewComments {
+		p = append(p, NoReviewComments{})
+	}
+	for _, pattern := range cfg.RequiredChecks {
+		p = append(p, RequiredCheck{Pattern: pattern})
+	}
+	if cfg.CombinedStatus {
+		p = append(p, CombinedStatus{Ignore: cfg.CombinedStatusIgnore})
+	}
+	if cfg.Mergeable {
+		p = append(p, Mergeable{})
+	}
+
+	return p
+}
+
+// Duration is a time.Duration that is encoded as a string, e.g. "72h", in
+// JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(parsed)
+	return nil
+}
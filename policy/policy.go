@@ -0,0 +1,294 @@
+// Package policy evaluates declarative merge policies.
+//
+// A policy is a list of conditions, such as "at least one approval" or "the
+// make_distcheck check succeeded". Conditions are evaluated against an Input,
+// a snapshot of the pull request's state collected by the caller, and each of
+// them reports whether it passed and why. Policies are usually built from a
+// Config, but conditions can also be composed directly using All and Any.
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Review is a pull request review.
+type Review struct {
+	Login       string
+	State       string
+	SubmittedAt time.Time
+	// CommitID is the commit the review was submitted for.
+	CommitID string
+}
+
+// LatestReviews returns the latest review of each reviewer. Reviews in the
+// "COMMENTED" and "PENDING" states do not replace an earlier approval or
+// change request, and dismissed reviews are dropped. If headSHA is not empty,
+// reviews of other commits are considered stale and are dropped, too.
+func LatestReviews(reviews []Review, headSHA string) []Review {
+	var (
+		latest = map[string]Review{}
+		order  []string
+	)
+	for _, r := range reviews {
+		switch r.State {
+		case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+		default:
+			continue
+		}
+
+		prev, ok := latest[r.Login]
+		if !ok {
+			order = append(order, r.Login)
+		} else if r.SubmittedAt.Before(prev.SubmittedAt) {
+			continue
+		}
+		latest[r.Login] = r
+	}
+
+	var ret []Review
+	for _, login := range order {
+		r := latest[login]
+		if r.State == "DISMISSED" {
+			continue
+		}
+		if headSHA != "" && r.CommitID != headSHA {
+			continue
+		}
+		ret = append(ret, r)
+	}
+	return ret
+}
+
+// Comment is a review comment on a pull request.
+type Comment struct {
+	Login string
+	URL   string
+}
+
+// Input is the state of a pull request a policy is evaluated against.
+type Input struct {
+	Author string
+	Base   string
+	Labels []string
+	Files  []string
+
+	// Reviews holds the reviews relevant for the policy, usually the
+	// result of LatestReviews.
+	Reviews []Review
+	// Comments holds the unresolved review comments.
+	Comments []Comment
+
+	// Statuses maps status contexts to their state, e.g. "success".
+	Statuses map[string]string
+	// Checks maps check run names to their conclusion, e.g. "success".
+	Checks map[string]string
+	// CombinedState is the combined state of all statuses.
+	CombinedState string
+
+	Mergeable bool
+	// LastPush is the time the head of the pull request was last updated.
+	LastPush time.Time
+
+	// TeamMembers maps "org/team" to the logins of the team's members.
+	TeamMembers map[string][]string
+	CodeOwners  *CodeOwners
+}
+
+// Result is the outcome of evaluating a single condition.
+type Result struct {
+	Condition string
+	OK        bool
+	// Reason explains why the condition failed. It may also be set for
+	// passing conditions.
+	Reason string
+	// Pending is true if the condition failed only because it is waiting
+	// for something, e.g. a check that has not completed yet.
+	Pending bool
+}
+
+func (r Result) String() string {
+	mark := "✗"
+	if r.OK {
+		mark = "✓"
+	}
+
+	if r.Reason == "" {
+		return fmt.Sprintf("%s %s", mark, r.Condition)
+	}
+	return fmt.Sprintf("%s %s: %s", mark, r.Condition, r.Reason)
+}
+
+// Report holds the results of all conditions of a policy.
+type Report []Result
+
+// OK returns true if all conditions passed.
+func (r Report) OK() bool {
+	for _, res := range r {
+		if !res.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed returns the results of all conditions that did not pass.
+func (r Report) Failed() Report {
+	var ret Report
+	for _, res := range r {
+		if !res.OK {
+			ret = append(ret, res)
+		}
+	}
+	return ret
+}
+
+// Pending returns true if at least one condition failed and all failed
+// conditions are pending.
+func (r Report) Pending() bool {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return false
+	}
+
+	for _, res := range failed {
+		if !res.Pending {
+			return false
+		}
+	}
+	return true
+}
+
+func (r Report) String() string {
+	var lines []string
+	for _, res := range r {
+		lines = append(lines, res.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Condition is a single requirement of a policy.
+type Condition interface {
+	Evaluate(in *Input) Result
+}
+
+// Policy is a list of conditions that must all pass.
+type Policy []Condition
+
+// Evaluate evaluates all conditions of the policy. All conditions are
+// evaluated, even if an earlier one failed, so that the report explains all
+// reasons why a pull request cannot be merged.
+func (p Policy) Evaluate(in *Input) Report {
+	var ret Report
+	for _, c := range p {
+		ret = append(ret, c.Evaluate(in))
+	}
+	return ret
+}
+
+// Teams returns the teams referenced by the policy's conditions. Callers use
+// this to populate Input.TeamMembers.
+func (p Policy) Teams() []string {
+	var ret []string
+	for _, c := range p {
+		ret = append(ret, teams(c)...)
+	}
+	return ret
+}
+
+// NeedsCodeOwners returns true if a condition of the policy requires
+// Input.CodeOwners and Input.Files to be set.
+func (p Policy) NeedsCodeOwners() bool {
+	for _, c := range p {
+		if needsCodeOwners(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func teams(c Condition) []string {
+	switch c := c.(type) {
+	case ApprovalFromTeam:
+		return []string{c.Team}
+	case allOf:
+		return Policy(c).Teams()
+	case anyOf:
+		return Policy(c).Teams()
+	}
+	return nil
+}
+
+func needsCodeOwners(c Condition) bool {
+	switch c := c.(type) {
+	case ApprovalFromCodeOwners:
+		return true
+	case allOf:
+		return Policy(c).NeedsCodeOwners()
+	case anyOf:
+		return Policy(c).NeedsCodeOwners()
+	}
+	return false
+}
+
+type allOf []Condition
+
+// All returns a condition that passes if all of conds pass.
+func All(conds ...Condition) Condition {
+	return allOf(conds)
+}
+
+func (a allOf) Evaluate(in *Input) Result {
+	var (
+		names   []string
+		reasons []string
+		ok      = true
+	)
+	for _, c := range a {
+		res := c.Evaluate(in)
+		names = append(names, res.Condition)
+		if !res.OK {
+			ok = false
+			reasons = append(reasons, res.Reason)
+		}
+	}
+
+	return Result{
+		Condition: strings.Join(names, " and "),
+		OK:        ok,
+		Reason:    strings.Join(reasons, "; "),
+	}
+}
+
+type anyOf []Condition
+
+// Any returns a condition that passes if at least one of conds passes.
+func Any(conds ...Condition) Condition {
+	return anyOf(conds)
+}
+
+func (a anyOf) Evaluate(in *Input) Result {
+	var (
+		names   []string
+		reasons []string
+	)
+	for _, c := range a {
+		res := c.Evaluate(in)
+		if res.OK {
+			return Result{
+				Condition: res.Condition,
+				OK:        true,
+				Reason:    res.Reason,
+			}
+		}
+		names = append(names, res.Condition)
+		reasons = append(reasons, res.Reason)
+	}
+
+	return Result{
+		Condition: strings.Join(names, " or "),
+		OK:        false,
+		Reason:    strings.Join(reasons, "; "),
+	}
+}
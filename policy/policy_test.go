@@ -0,0 +1,287 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPolicy(t *testing.T) {
+	lastPush := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	cfg, err := Parse([]byte(`{
+		"min_approvals": 1,
+		"approval_max_age": "24h",
+		"no_changes_requested": true,
+		"no_review_comments": true,
+		"required_checks": ["ChangeLog", "make_*"],
+		"forbidden_labels": ["Do not merge"],
+		"base_branches": ["main", "collectd-*"]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := cfg.Policy()
+
+	base := Input{
+		Author: "octo",
+		Base:   "main",
+		Reviews: []Review{
+			{Login: "alice", State: "APPROVED", SubmittedAt: lastPush.Add(time.Hour)},
+		},
+		Statuses: map[string]string{"ChangeLog": "success"},
+		Checks:   map[string]string{"make_distcheck": "success", "make_check": "success"},
+		LastPush: lastPush,
+	}
+
+	cases := []struct {
+		name   string
+		modify func(in *Input)
+		want   bool
+	}{
+		{"ok", func(in *Input) {}, true},
+		{"stale approval", func(in *Input) {
+			in.Reviews[0].SubmittedAt = lastPush.Add(-48 * time.Hour)
+		}, false},
+		{"approval within max age", func(in *Input) {
+			in.Reviews[0].SubmittedAt = lastPush.Add(-12 * time.Hour)
+		}, true},
+		{"changes requested", func(in *Input) {
+			in.Reviews = append(in.Reviews, Review{Login: "bob", State: "CHANGES_REQUESTED"})
+		}, false},
+		{"review comment", func(in *Input) {
+			in.Comments = []Comment{{Login: "bob", URL: "https://example.com/"}}
+		}, false},
+		{"check missing", func(in *Input) {
+			in.Statuses = nil
+		}, false},
+		{"check failed", func(in *Input) {
+			in.Checks = map[string]string{"make_distcheck": "success", "make_check": "failure"}
+		}, false},
+		{"forbidden label", func(in *Input) {
+			in.Labels = []string{"Fix", "Do not merge"}
+		}, false},
+		{"release branch", func(in *Input) {
+			in.Base = "collectd-5.12"
+		}, true},
+		{"other branch", func(in *Input) {
+			in.Base = "feature"
+		}, false},
+	}
+
+	for _, tc := range cases {
+		in := base
+		in.Reviews = append([]Review(nil), base.Reviews...)
+		tc.modify(&in)
+
+		r := p.Evaluate(&in)
+		if got := r.OK(); got != tc.want {
+			t.Errorf("%s: Evaluate() = %v, want %v\n%s", tc.name, got, tc.want, r)
+		}
+		if len(r) != len(p) {
+			t.Errorf("%s: len(Evaluate()) = %d, want %d", tc.name, len(r), len(p))
+		}
+	}
+}
+
+func TestAny(t *testing.T) {
+	c := Any(
+		ApprovalFromTeam{Team: "collectd/core"},
+		MinApprovals{Count: 2},
+	)
+
+	in := &Input{
+		Reviews: []Review{
+			{Login: "alice", State: "APPROVED"},
+		},
+		TeamMembers: map[string][]string{
+			"collectd/core": {"bob"},
+		},
+	}
+	if c.Evaluate(in).OK {
+		t.Errorf("Any().Evaluate() = true, want false")
+	}
+
+	in.Reviews = append(in.Reviews, Review{Login: "bob", State: "APPROVED"})
+	if !c.Evaluate(in).OK {
+		t.Errorf("Any().Evaluate() = false, want true")
+	}
+
+	if got := (Policy{c}).Teams(); len(got) != 1 || got[0] != "collectd/core" {
+		t.Errorf("Teams() = %q, want [\"collectd/core\"]", got)
+	}
+}
+
+func TestCodeOwners(t *testing.T) {
+	co := ParseCodeOwners(`# Code owners
+*                 @octo
+*.pod             @docs-person # documentation
+/src/write_*.c    @collectd/writers
+src/daemon/       @collectd/core
+`)
+
+	cases := []struct {
+		file string
+		want string
+	}{
+		{"README", "@octo"},
+		{"src/collectd.conf.pod", "@docs-person"},
+		{"src/write_http.c", "@collectd/writers"},
+		{"contrib/src/write_http.c", "@octo"},
+		{"src/daemon/plugin.c", "@collectd/core"},
+		{"src/daemon/utils/common.c", "@collectd/core"},
+	}
+
+	for _, tc := range cases {
+		got := co.Owners(tc.file)
+		if len(got) != 1 || got[0] != tc.want {
+			t.Errorf("Owners(%q) = %q, want [%q]", tc.file, got, tc.want)
+		}
+	}
+
+	in := &Input{
+		Files:      []string{"src/write_http.c", "README"},
+		CodeOwners: co,
+		Reviews: []Review{
+			{Login: "octo", State: "APPROVED"},
+		},
+		TeamMembers: map[string][]string{
+			"collectd/writers": {"alice"},
+		},
+	}
+	if res := (ApprovalFromCodeOwners{}).Evaluate(in); res.OK {
+		t.Errorf("ApprovalFromCodeOwners.Evaluate() = %v, want failure", res)
+	}
+
+	in.Reviews = append(in.Reviews, Review{Login: "alice", State: "APPROVED"})
+	if res := (ApprovalFromCodeOwners{}).Evaluate(in); !res.OK {
+		t.Errorf("ApprovalFromCodeOwners.Evaluate() = %v, want success", res)
+	}
+}
+
+func TestMatchCodeOwners(t *testing.T) {
+	cases := []struct {
+		pattern, file string
+		want          bool
+	}{
+		{"*", "src/daemon/plugin.c", true},
+		{"src/*", "src/cpu.c", true},
+		{"src/*", "src/daemon/plugin.c", false},
+		{"/src/*.c", "src/utils/common/common.c", false},
+		{"src/daemon/", "src/daemon/plugin.c", true},
+		{"src/daemon/", "src/daemon/utils/common.c", true},
+		{"src/daemon/", "src/daemon.c", false},
+		{"docs/**", "docs/a/b/c.md", true},
+		{"*.pod", "src/daemon/collectd.pod", true},
+		{"/README", "src/README", false},
+		{"README", "src/README", true},
+	}
+
+	for _, tc := range cases {
+		if got := matchCodeOwners(tc.pattern, tc.file); got != tc.want {
+			t.Errorf("matchCodeOwners(%q, %q) = %v, want %v", tc.pattern, tc.file, got, tc.want)
+		}
+	}
+}
+
+func TestLatestReviews(t *testing.T) {
+	t0 := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	reviews := []Review{
+		{Login: "alice", State: "CHANGES_REQUESTED", SubmittedAt: t0, CommitID: "a"},
+		{Login: "bob", State: "APPROVED", SubmittedAt: t0, CommitID: "a"},
+		{Login: "alice", State: "APPROVED", SubmittedAt: t0.Add(time.Hour), CommitID: "b"},
+		{Login: "bob", State: "COMMENTED", SubmittedAt: t0.Add(time.Hour), CommitID: "b"},
+		{Login: "carol", State: "CHANGES_REQUESTED", SubmittedAt: t0, CommitID: "a"},
+		{Login: "carol", State: "DISMISSED", SubmittedAt: t0.Add(time.Hour), CommitID: "a"},
+	}
+
+	cases := []struct {
+		headSHA string
+		want    []string
+	}{
+		{"", []string{"alice:APPROVED", "bob:APPROVED"}},
+		{"b", []string{"alice:APPROVED"}},
+	}
+
+	for _, tc := range cases {
+		var got []string
+		for _, r := range LatestReviews(reviews, tc.headSHA) {
+			got = append(got, r.Login+":"+r.State)
+		}
+
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("LatestReviews(%q) = %q, want %q", tc.headSHA, got, tc.want)
+		}
+	}
+}
+
+func TestPending(t *testing.T) {
+	p := Policy{
+		RequiredCheck{Pattern: "make_distcheck"},
+		CombinedStatus{},
+	}
+
+	cases := []struct {
+		in   Input
+		want bool
+	}{
+		{Input{CombinedState: "pending"}, true},
+		{Input{CombinedState: "pending", Checks: map[string]string{"make_distcheck": ""}}, true},
+		{Input{CombinedState: "success", Checks: map[string]string{"make_distcheck": "failure"}}, false},
+		{Input{CombinedState: "failure", Checks: map[string]string{"make_distcheck": ""}}, false},
+		{Input{CombinedState: "success", Checks: map[string]string{"make_distcheck": "success"}}, false},
+	}
+
+	for _, tc := range cases {
+		if got := p.Evaluate(&tc.in).Pending(); got != tc.want {
+			t.Errorf("Evaluate(%+v).Pending() = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestCombinedState(t *testing.T) {
+	cases := []struct {
+		statuses map[string]string
+		want     string
+	}{
+		{nil, "success"},
+		{map[string]string{"ChangeLog": "success", "Size": "success"}, "success"},
+		{map[string]string{"ChangeLog": "success", "Size": "pending"}, "pending"},
+		{map[string]string{"ChangeLog": "error", "Size": "pending"}, "failure"},
+		{map[string]string{"ChangeLog": "failure", "Size": "success"}, "failure"},
+	}
+
+	for _, tc := range cases {
+		if got := CombinedState(tc.statuses); got != tc.want {
+			t.Errorf("CombinedState(%v) = %q, want %q", tc.statuses, got, tc.want)
+		}
+	}
+}
+
+func TestCombinedStatusIgnore(t *testing.T) {
+	in := &Input{
+		CombinedState: "failure",
+		Statuses: map[string]string{
+			"ChangeLog":            "success",
+			"Conventional Commits": "failure",
+		},
+	}
+
+	if res := (CombinedStatus{}).Evaluate(in); res.OK {
+		t.Errorf("CombinedStatus{}.Evaluate() = %v, want failure", res)
+	}
+	if res := (CombinedStatus{Ignore: []string{"Conventional*"}}).Evaluate(in); !res.OK {
+		t.Errorf("CombinedStatus{Ignore}.Evaluate() = %v, want success", res)
+	}
+}
+
+func TestParseDismissStaleReviews(t *testing.T) {
+	cfg, err := Parse([]byte(`{"min_approvals": 1, "dismiss_stale_reviews": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.DismissStaleReviews {
+		t.Errorf("Parse() = %+v, want DismissStaleReviews", cfg)
+	}
+}
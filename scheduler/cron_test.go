@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// A Wednesday.
+	base := time.Date(2021, 6, 2, 10, 17, 30, 0, time.UTC)
+
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2021, 6, 2, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2021, 6, 2, 10, 30, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2021, 6, 2, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2021, 6, 3, 0, 0, 0, 0, time.UTC)},
+		{"30 3 * * *", time.Date(2021, 6, 3, 3, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * 1-5", time.Date(2021, 6, 2, 13, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2021, 6, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2021, 6, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// Day of month or day of week.
+		{"0 0 13 * 5", time.Date(2021, 6, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tc := range cases {
+		s, err := Parse(tc.spec)
+		if err != nil {
+			t.Errorf("Parse(%q) = %v", tc.spec, err)
+			continue
+		}
+		if got := s.Next(base); !got.Equal(tc.want) {
+			t.Errorf("Parse(%q).Next(%v) = %v, want %v", tc.spec, base, got, tc.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) = nil, want error", spec)
+		}
+	}
+}
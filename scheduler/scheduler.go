@@ -0,0 +1,275 @@
+// Package scheduler runs periodic jobs.
+//
+// Actions register jobs with Register, similar to registering event handlers.
+// The jobs are run by RunDue, which is called periodically: by App Engine cron
+// requesting Handler, or by Run in standalone mode. A lease stored in
+// Datastore ensures that a job is not run by multiple instances concurrently,
+// and the time and result of the last run are recorded there, too.
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/datastore"
+	"github.com/mtraver/gaelog"
+	"github.com/octo/ghbot/config"
+	"go.opencensus.io/trace"
+)
+
+const kind = "ScheduledJob"
+
+// leaseDuration is how long a job may run before another instance may start
+// it again.
+const leaseDuration = 15 * time.Minute
+
+type job struct {
+	name     string
+	spec     string
+	schedule *Schedule
+	run      func(context.Context) error
+}
+
+var (
+	mu   sync.Mutex
+	jobs = map[string]job{}
+)
+
+// Register registers a job called name that runs according to the cron
+// specification spec, e.g. "0 3 * * *" for daily at 03:00 UTC. It panics if
+// spec is invalid.
+func Register(name, spec string, run func(context.Context) error) {
+	s, err := Parse(spec)
+	if err != nil {
+		panic(fmt.Sprintf("scheduler.Register(%q): %v", name, err))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	jobs[name] = job{
+		name:     name,
+		spec:     spec,
+		schedule: s,
+		run:      run,
+	}
+}
+
+func registered() []job {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var ret []job
+	for _, j := range jobs {
+		ret = append(ret, j)
+	}
+	sort.Slice(ret, func(i, k int) bool {
+		return ret[i].name < ret[k].name
+	})
+	return ret
+}
+
+// State is the bookkeeping of a job, stored in Datastore.
+type State struct {
+	Name         string
+	Spec         string
+	LastRun      time.Time
+	LastDuration time.Duration
+	LastError    string `datastore:",noindex"`
+	// LeaseHolder identifies the run currently holding the lease, if any.
+	LeaseHolder string
+	LeaseUntil  time.Time
+}
+
+func key(name string) *datastore.Key {
+	return datastore.NameKey(kind, name, nil)
+}
+
+func newHolder() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// acquire takes the lease of j if the job is due at now and the lease isn't
+// held by anyone else. It returns the lease holder ID, or "" if the job must
+// not be run.
+func acquire(ctx context.Context, db *datastore.Client, j job, now time.Time) (string, error) {
+	holder := ""
+	_, err := db.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		holder = ""
+
+		var s State
+		if err := tx.Get(key(j.name), &s); err != nil && !errors.Is(err, datastore.ErrNoSuchEntity) {
+			return err
+		}
+
+		// Jobs that have never run start their schedule now. Otherwise
+		// every deployment of a new job would run it immediately,
+		// regardless of its schedule.
+		if s.LastRun.IsZero() {
+			s.Name = j.name
+			s.Spec = j.spec
+			s.LastRun = now
+			_, err := tx.Put(key(j.name), &s)
+			return err
+		}
+		if next := j.schedule.Next(s.LastRun); next.IsZero() || next.After(now) {
+			return nil
+		}
+		if s.LeaseHolder != "" && s.LeaseUntil.After(now) {
+			return nil
+		}
+
+		s.Name = j.name
+		s.Spec = j.spec
+		s.LeaseHolder = newHolder()
+		s.LeaseUntil = now.Add(leaseDuration)
+		if _, err := tx.Put(key(j.name), &s); err != nil {
+			return err
+		}
+
+		holder = s.LeaseHolder
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("scheduler: acquiring lease for %q: %w", j.name, err)
+	}
+	return holder, nil
+}
+
+// release records the result of a run and releases the lease.
+func release(ctx context.Context, db *datastore.Client, j job, holder string, start time.Time, runErr error) error {
+	_, err := db.RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		var s State
+		if err := tx.Get(key(j.name), &s); err != nil && !errors.Is(err, datastore.ErrNoSuchEntity) {
+			return err
+		}
+
+		s.Name = j.name
+		s.Spec = j.spec
+		s.LastRun = start
+		s.LastDuration = time.Since(start)
+		s.LastError = ""
+		if runErr != nil {
+			s.LastError = runErr.Error()
+		}
+		if s.LeaseHolder == holder {
+			s.LeaseHolder = ""
+			s.LeaseUntil = time.Time{}
+		}
+
+		_, err := tx.Put(key(j.name), &s)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("scheduler: recording run of %q: %w", j.name, err)
+	}
+	return nil
+}
+
+// RunDue runs all jobs that are due and returns the names of the jobs run.
+// Errors returned by jobs are logged and recorded, but not returned.
+func RunDue(ctx context.Context) ([]string, error) {
+	db, err := config.Datastore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []string
+	for _, j := range registered() {
+		now := time.Now()
+		holder, err := acquire(ctx, db, j, now)
+		if err != nil {
+			return ran, err
+		}
+		if holder == "" {
+			continue
+		}
+
+		ctx, span := trace.StartSpan(ctx, "Job "+j.name)
+		gaelog.Infof(ctx, "scheduler: running %q", j.name)
+		runErr := j.run(ctx)
+		if runErr != nil {
+			gaelog.Errorf(ctx, "scheduler: %q failed: %v", j.name, runErr)
+		}
+		span.End()
+
+		ran = append(ran, j.name)
+		if err := release(ctx, db, j, holder, now, runErr); err != nil {
+			return ran, err
+		}
+	}
+
+	return ran, nil
+}
+
+// States returns the bookkeeping of all registered jobs.
+func States(ctx context.Context) ([]State, error) {
+	db, err := config.Datastore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []State
+	for _, j := range registered() {
+		s := State{Name: j.name, Spec: j.spec}
+		if err := db.Get(ctx, key(j.name), &s); err != nil && !errors.Is(err, datastore.ErrNoSuchEntity) {
+			return nil, fmt.Errorf("scheduler: %q: %w", j.name, err)
+		}
+		ret = append(ret, s)
+	}
+	return ret, nil
+}
+
+// Handler runs all due jobs and responds with the state of all jobs as JSON.
+// It is meant to be requested by App Engine cron.
+func Handler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if _, err := RunDue(ctx); err != nil {
+		gaelog.Errorf(ctx, "RunDue: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	states, err := States(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(states); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Run calls RunDue every interval until ctx is canceled. This is used when
+// running outside of App Engine, where there is no cron service.
+func Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		if _, err := RunDue(ctx); err != nil {
+			gaelog.Errorf(ctx, "RunDue: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}